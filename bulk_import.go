@@ -0,0 +1,489 @@
+package loops
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Whats-A-MattR/loops-go-sdk/internal/atomicfile"
+)
+
+// ImportFormat is the input format for ImportContacts.
+type ImportFormat int
+
+const (
+	// ImportCSV reads CSV with a header row naming the columns.
+	ImportCSV ImportFormat = iota
+	// ImportJSONL reads one JSON object per line.
+	ImportJSONL
+)
+
+// ImportMapping maps source columns (CSV headers or JSONL keys) to contact fields. It has JSON tags so it can be
+// kept in a config file.
+type ImportMapping struct {
+	// Columns maps a source column to a target: a ContactRequest field ("email", "firstName", "lastName",
+	// "subscribed", "userGroup", "userId"), "mailingLists.<listId>" for list membership, or any other name for a
+	// custom contact property.
+	Columns map[string]string `json:"columns"`
+	// Types gives the type of custom properties ("string", "number", "boolean" or "date"). CSV values are strings
+	// and are converted accordingly; untyped properties are sent as read.
+	Types map[string]string `json:"types,omitempty"`
+	// IncludeUnmapped sends columns missing from Columns as custom properties of the same name.
+	IncludeUnmapped bool `json:"includeUnmapped,omitempty"`
+}
+
+// ImportOptions configures ImportContacts.
+type ImportOptions struct {
+	Format  ImportFormat
+	Mapping ImportMapping
+	// Concurrency is the number of rows processed in parallel. Default 4.
+	Concurrency int
+	// RateLimit caps requests per second across workers. Default 10 (the Loops API limit); negative disables.
+	RateLimit float64
+	// CreateOnly uses CreateContact and reports existing contacts (409) as skipped. By default rows are upserted
+	// with UpdateContact, which creates missing contacts.
+	CreateOnly bool
+	// CheckpointPath, if set, records progress so an interrupted import resumes after the last row for which every
+	// earlier row has finished. Resuming assumes the same input; delete the file to start over.
+	CheckpointPath string
+	// Report, if set, receives one JSON-encoded ImportResult per processed row, in completion order.
+	Report io.Writer
+}
+
+// ImportStatus is the outcome of one imported row.
+type ImportStatus string
+
+const (
+	ImportUpserted ImportStatus = "upserted"
+	ImportCreated  ImportStatus = "created"
+	ImportSkipped  ImportStatus = "skipped"
+	ImportFailed   ImportStatus = "failed"
+)
+
+// ImportResult is the per-row report entry. Row is 1-based and counts data rows (not the CSV header).
+type ImportResult struct {
+	Row       int          `json:"row"`
+	Email     string       `json:"email,omitempty"`
+	UserID    string       `json:"userId,omitempty"`
+	Status    ImportStatus `json:"status"`
+	ContactID string       `json:"contactId,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// ImportSummary counts the outcomes of an ImportContacts run.
+type ImportSummary struct {
+	Processed int // rows processed in this run
+	Succeeded int
+	Skipped   int
+	Failed    int
+	Resumed   int // rows skipped because the checkpoint showed them done
+}
+
+// importCheckpoint is the on-disk progress record.
+type importCheckpoint struct {
+	Row int `json:"row"`
+}
+
+type importRow struct {
+	n      int
+	fields map[string]interface{}
+	err    error
+}
+
+// ImportContacts streams contacts from r and creates or upserts each row with bounded concurrency under a rate
+// limit. Row-level problems (bad values, API errors) are reported per row and do not stop the import; an error is
+// returned only for unreadable input, checkpoint or report I/O failures, or ctx cancellation.
+func (c *Client) ImportContacts(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportSummary, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.RateLimit == 0 {
+		opts.RateLimit = 10
+	}
+	var limiter *rateLimiter
+	if opts.RateLimit > 0 {
+		limiter = newRateLimiter(opts.RateLimit, opts.Concurrency)
+	}
+	done := 0
+	if opts.CheckpointPath != "" {
+		cp, err := readImportCheckpoint(opts.CheckpointPath)
+		if err != nil {
+			return nil, err
+		}
+		done = cp.Row
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	summary := &ImportSummary{}
+	rows := make(chan importRow)
+	results := make(chan ImportResult)
+	var readErr error
+
+	go func() {
+		defer close(rows)
+		readErr = readImportRows(ctx, r, opts.Format, func(row importRow) bool {
+			if row.n <= done {
+				summary.Resumed++
+				return true
+			}
+			select {
+			case rows <- row:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				results <- c.importRow(ctx, row, opts, limiter)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Track a contiguous watermark: rows finish out of order, so the checkpoint only advances past a row once
+	// every earlier row has finished.
+	finished := make(map[int]bool)
+	watermark := done
+	var enc *json.Encoder
+	if opts.Report != nil {
+		enc = json.NewEncoder(opts.Report)
+	}
+	var firstErr error
+	for res := range results {
+		if firstErr != nil {
+			continue // drain
+		}
+		if ctx.Err() != nil && res.Status == ImportFailed {
+			continue // cancelled mid-request; leave the row for the next run
+		}
+		summary.Processed++
+		switch res.Status {
+		case ImportFailed:
+			summary.Failed++
+		case ImportSkipped:
+			summary.Skipped++
+		default:
+			summary.Succeeded++
+		}
+		if enc != nil {
+			if err := enc.Encode(res); err != nil {
+				firstErr = fmt.Errorf("loops: write import report: %w", err)
+				cancel()
+				continue
+			}
+		}
+		finished[res.Row] = true
+		advanced := false
+		for finished[watermark+1] {
+			delete(finished, watermark+1)
+			watermark++
+			advanced = true
+		}
+		if advanced && opts.CheckpointPath != "" {
+			if err := writeImportCheckpoint(opts.CheckpointPath, importCheckpoint{Row: watermark}); err != nil {
+				firstErr = err
+				cancel()
+			}
+		}
+	}
+	if firstErr != nil {
+		return summary, firstErr
+	}
+	if readErr != nil {
+		return summary, readErr
+	}
+	if err := ctx.Err(); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+func (c *Client) importRow(ctx context.Context, row importRow, opts ImportOptions, limiter *rateLimiter) ImportResult {
+	res := ImportResult{Row: row.n}
+	if row.err != nil {
+		res.Status, res.Error = ImportFailed, row.err.Error()
+		return res
+	}
+	req, err := opts.Mapping.contactRequest(row.fields)
+	if err != nil {
+		res.Status, res.Error = ImportFailed, err.Error()
+		return res
+	}
+	res.Email, res.UserID = req.Email, req.UserID
+	if limiter != nil {
+		if err := limiter.wait(ctx); err != nil {
+			res.Status, res.Error = ImportFailed, err.Error()
+			return res
+		}
+	}
+	var out *ContactSuccessResponse
+	if opts.CreateOnly {
+		out, err = c.CreateContact(ctx, req)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			res.Status, res.Error = ImportSkipped, apiErr.Message
+			return res
+		}
+		res.Status = ImportCreated
+	} else {
+		out, err = c.UpdateContact(ctx, &ContactUpdateRequest{
+			Email:        req.Email,
+			FirstName:    req.FirstName,
+			LastName:     req.LastName,
+			Subscribed:   req.Subscribed,
+			UserGroup:    req.UserGroup,
+			UserID:       req.UserID,
+			MailingLists: req.MailingLists,
+			Extra:        req.Extra,
+		})
+		res.Status = ImportUpserted
+	}
+	if err != nil {
+		res.Status, res.Error = ImportFailed, err.Error()
+		return res
+	}
+	res.ContactID = out.ID
+	return res
+}
+
+// readImportRows calls emit for each data row until the input ends or emit returns false.
+func readImportRows(ctx context.Context, r io.Reader, format ImportFormat, emit func(importRow) bool) error {
+	switch format {
+	case ImportCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("loops: read CSV header: %w", err)
+		}
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff") // UTF-8 BOM from spreadsheet exports
+		}
+		for n := 1; ctx.Err() == nil; n++ {
+			rec, err := cr.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("loops: read CSV row %d: %w", n, err)
+			}
+			row := importRow{n: n, fields: make(map[string]interface{}, len(header))}
+			if len(rec) > len(header) {
+				row.err = fmt.Errorf("row has %d fields, header has %d", len(rec), len(header))
+			}
+			for i, v := range rec {
+				if i < len(header) {
+					row.fields[header[i]] = v
+				}
+			}
+			if !emit(row) {
+				return nil
+			}
+		}
+		return nil
+	case ImportJSONL:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 10*1024*1024)
+		n := 0
+		for sc.Scan() && ctx.Err() == nil {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			n++
+			row := importRow{n: n}
+			if err := decodeJSONLine(line, &row.fields); err != nil {
+				row.err = fmt.Errorf("invalid JSON: %w", err)
+			}
+			if !emit(row) {
+				return nil
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return fmt.Errorf("loops: read JSONL: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("loops: unknown import format %d", format)
+}
+
+// contactRequest builds the request for one row. Empty strings are treated as absent values.
+func (m ImportMapping) contactRequest(fields map[string]interface{}) (*ContactRequest, error) {
+	req := &ContactRequest{}
+	for col, val := range fields {
+		target, ok := m.Columns[col]
+		if !ok {
+			if !m.IncludeUnmapped {
+				continue
+			}
+			target = col
+		}
+		if val == nil {
+			continue
+		}
+		if s, isString := val.(string); isString && strings.TrimSpace(s) == "" {
+			continue
+		}
+		var err error
+		switch target {
+		case "email":
+			req.Email = strings.TrimSpace(importString(val))
+		case "firstName":
+			req.FirstName = importString(val)
+		case "lastName":
+			req.LastName = importString(val)
+		case "userGroup":
+			req.UserGroup = importString(val)
+		case "userId":
+			req.UserID = importString(val)
+		case "subscribed":
+			var b bool
+			b, err = importBool(val)
+			req.Subscribed = &b
+		default:
+			if listID := strings.TrimPrefix(target, "mailingLists."); listID != target {
+				var b bool
+				if b, err = importBool(val); err == nil {
+					if req.MailingLists == nil {
+						req.MailingLists = make(map[string]bool)
+					}
+					req.MailingLists[listID] = b
+				}
+				break
+			}
+			var v interface{}
+			if v, err = importValue(val, m.Types[target]); err == nil {
+				if req.Extra == nil {
+					req.Extra = make(map[string]interface{})
+				}
+				req.Extra[target] = v
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", col, err)
+		}
+	}
+	return req, nil
+}
+
+func importBool(v interface{}) (bool, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return false, fmt.Errorf("invalid boolean %v", v)
+		}
+		return f != 0, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "true", "yes", "y", "1":
+			return true, nil
+		case "false", "no", "n", "0":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("invalid boolean %v", v)
+}
+
+func importValue(v interface{}, typ string) (interface{}, error) {
+	s, isString := v.(string)
+	switch PropertyType(typ) {
+	case PropertyNumber:
+		if !isString {
+			if _, ok := v.(json.Number); ok {
+				return v, nil // sent as written, without float64 rounding
+			}
+			return nil, fmt.Errorf("invalid number %v", v)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return f, nil
+	case PropertyBoolean:
+		return importBool(v)
+	case PropertyString, PropertyDate:
+		if isString {
+			return s, nil
+		}
+		return importString(v), nil
+	}
+	return v, nil
+}
+
+// decodeJSONLine decodes one JSONL object with numbers kept as json.Number, so IDs and large integers are not
+// rounded through float64.
+func decodeJSONLine(line string, fields *map[string]interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(fields); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after object")
+	}
+	return nil
+}
+
+// importString formats a value for a string field; JSON numbers keep their literal text (1234567, not 1.234567e+06).
+func importString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func readImportCheckpoint(path string) (importCheckpoint, error) {
+	var cp importCheckpoint
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, fmt.Errorf("loops: read import checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return cp, fmt.Errorf("loops: parse import checkpoint %s: %w", path, err)
+	}
+	return cp, nil
+}
+
+// writeImportCheckpoint replaces the checkpoint atomically.
+func writeImportCheckpoint(path string, cp importCheckpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("loops: write import checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package loops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// importServer records upserted contacts by email and fails any contact whose email starts with "fail".
+func importServer(t *testing.T) (*Client, func() map[string]map[string]interface{}) {
+	t.Helper()
+	var mu sync.Mutex
+	seen := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		email, _ := body["email"].(string)
+		if strings.HasPrefix(email, "fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success":false,"message":"bad contact"}`))
+			return
+		}
+		if r.URL.Path == "/contacts/create" && email == "exists@example.com" {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"success":false,"message":"Email already on list."}`))
+			return
+		}
+		mu.Lock()
+		seen[email] = body
+		mu.Unlock()
+		w.Write([]byte(`{"success":true,"id":"id_` + email + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("key", WithBaseURL(server.URL)), func() map[string]map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return seen
+	}
+}
+
+func TestClient_ImportContacts_CSVMapping(t *testing.T) {
+	client, seen := importServer(t)
+	input := "\ufeffE-mail,First,Plan,Seats,Newsletter,Notes\n" +
+		"a@example.com,Ann,pro,3,yes,hello\n" +
+		"b@example.com,Bob,,x,no,\n" +
+		"fail@example.com,Fay,free,1,1,\n"
+	var report bytes.Buffer
+	summary, err := client.ImportContacts(context.Background(), strings.NewReader(input), ImportOptions{
+		Format: ImportCSV,
+		Mapping: ImportMapping{
+			Columns: map[string]string{
+				"E-mail":     "email",
+				"First":      "firstName",
+				"Plan":       "plan",
+				"Seats":      "seats",
+				"Newsletter": "mailingLists.list_news",
+			},
+			Types: map[string]string{"seats": "number"},
+		},
+		RateLimit: -1,
+		Report:    &report,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Processed != 3 || summary.Succeeded != 1 || summary.Failed != 2 {
+		t.Errorf("summary: %+v", summary)
+	}
+	a := seen()["a@example.com"]
+	if a == nil || a["firstName"] != "Ann" || a["plan"] != "pro" || a["seats"] != 3.0 {
+		t.Errorf("a@example.com body: %v", a)
+	}
+	if lists, _ := a["mailingLists"].(map[string]interface{}); lists["list_news"] != true {
+		t.Errorf("mailingLists: %v", a["mailingLists"])
+	}
+	if _, ok := a["Notes"]; ok {
+		t.Error("unmapped column should not be sent")
+	}
+
+	results := map[int]ImportResult{}
+	for _, line := range strings.Split(strings.TrimSpace(report.String()), "\n") {
+		var res ImportResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatal(err)
+		}
+		results[res.Row] = res
+	}
+	if results[1].Status != ImportUpserted || results[1].ContactID != "id_a@example.com" {
+		t.Errorf("row 1: %+v", results[1])
+	}
+	if results[2].Status != ImportFailed || !strings.Contains(results[2].Error, `"Seats"`) {
+		t.Errorf("row 2 (bad number): %+v", results[2])
+	}
+	if results[3].Status != ImportFailed || !strings.Contains(results[3].Error, "bad contact") {
+		t.Errorf("row 3 (API error): %+v", results[3])
+	}
+}
+
+func TestClient_ImportContacts_JSONLCreateOnlyAndResume(t *testing.T) {
+	client, seen := importServer(t)
+	checkpoint := filepath.Join(t.TempDir(), "import.checkpoint")
+	if err := os.WriteFile(checkpoint, []byte(`{"row":1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input := `{"email":"already@example.com"}` + "\n\n" +
+		`{"email":"exists@example.com","vip":true}` + "\n" +
+		`{"email":"c@example.com","vip":true,"score":7}` + "\n" +
+		`not json` + "\n"
+	summary, err := client.ImportContacts(context.Background(), strings.NewReader(input), ImportOptions{
+		Format:         ImportJSONL,
+		Mapping:        ImportMapping{Columns: map[string]string{"email": "email"}, IncludeUnmapped: true},
+		CreateOnly:     true,
+		Concurrency:    2,
+		RateLimit:      -1,
+		CheckpointPath: checkpoint,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Resumed != 1 || summary.Processed != 3 || summary.Succeeded != 1 || summary.Skipped != 1 || summary.Failed != 1 {
+		t.Errorf("summary: %+v", summary)
+	}
+	if _, ok := seen()["already@example.com"]; ok {
+		t.Error("row before the checkpoint was re-imported")
+	}
+	if c := seen()["c@example.com"]; c == nil || c["vip"] != true || c["score"] != 7.0 {
+		t.Errorf("c@example.com body: %v", c)
+	}
+	cp, err := readImportCheckpoint(checkpoint)
+	if err != nil || cp.Row != 4 {
+		t.Errorf("checkpoint: %+v, %v", cp, err)
+	}
+}
+
+func TestClient_ImportContacts_JSONLNumericUserID(t *testing.T) {
+	client, seen := importServer(t)
+	input := `{"email":"a@example.com","id":1234567}` + "\n" +
+		`{"email":"b@example.com","id":9007199254740993,"plan":2}` + "\n"
+	summary, err := client.ImportContacts(context.Background(), strings.NewReader(input), ImportOptions{
+		Format:    ImportJSONL,
+		Mapping:   ImportMapping{Columns: map[string]string{"email": "email", "id": "userId", "plan": "planCode"}, Types: map[string]string{"planCode": "string"}},
+		RateLimit: -1,
+	})
+	if err != nil || summary.Succeeded != 2 {
+		t.Fatalf("summary %+v, err %v", summary, err)
+	}
+	if id := seen()["a@example.com"]["userId"]; id != "1234567" {
+		t.Errorf("userId: %v", id)
+	}
+	if b := seen()["b@example.com"]; b["userId"] != "9007199254740993" || b["planCode"] != "2" {
+		t.Errorf("b@example.com body: %v", b)
+	}
+}
+
+func TestClient_ImportContacts_MalformedCSVStops(t *testing.T) {
+	client, _ := importServer(t)
+	_, err := client.ImportContacts(context.Background(), strings.NewReader("email\n\"unterminated\n"), ImportOptions{RateLimit: -1})
+	if err == nil {
+		t.Fatal("expected error for malformed CSV")
+	}
+}
@@ -0,0 +1,229 @@
+package loops
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheResource identifies a group of cached responses for invalidation.
+type CacheResource string
+
+const (
+	CacheLists             CacheResource = "lists"             // GetLists
+	CacheContactProperties CacheResource = "contactProperties" // ListContactProperties
+	CacheThemes            CacheResource = "themes"            // ListThemes
+	CacheTheme             CacheResource = "theme"             // GetTheme
+	CacheComponents        CacheResource = "components"        // ListComponents
+	CacheTransactionals    CacheResource = "transactionals"    // ListTransactionals
+)
+
+// CacheConfig sets the TTL for each cacheable resource. A zero TTL leaves that resource uncached.
+type CacheConfig struct {
+	Lists             time.Duration
+	ContactProperties time.Duration
+	Themes            time.Duration
+	Theme             time.Duration
+	Components        time.Duration
+	Transactionals    time.Duration
+}
+
+// DefaultCacheConfig caches every cacheable resource for five minutes.
+func DefaultCacheConfig() CacheConfig {
+	const ttl = 5 * time.Minute
+	return CacheConfig{
+		Lists:             ttl,
+		ContactProperties: ttl,
+		Themes:            ttl,
+		Theme:             ttl,
+		Components:        ttl,
+		Transactionals:    ttl,
+	}
+}
+
+func (cfg CacheConfig) ttl(r CacheResource) time.Duration {
+	switch r {
+	case CacheLists:
+		return cfg.Lists
+	case CacheContactProperties:
+		return cfg.ContactProperties
+	case CacheThemes:
+		return cfg.Themes
+	case CacheTheme:
+		return cfg.Theme
+	case CacheComponents:
+		return cfg.Components
+	case CacheTransactionals:
+		return cfg.Transactionals
+	}
+	return 0
+}
+
+// WithCache enables an in-memory read-through cache for slow-changing resources (GetLists, ListContactProperties,
+// ListThemes, GetTheme, ListComponents, ListTransactionals). Concurrent identical fetches share one request, which
+// keeps running when a caller's context is cancelled. Responses are cached as raw JSON, so each caller receives
+// its own copy. Mutations made through the client (e.g. CreateContactProperty) invalidate the affected resource.
+func WithCache(cfg CacheConfig) ClientOption {
+	return func(c *Client) {
+		c.cache = &responseCache{
+			cfg:      cfg,
+			entries:  make(map[string]cacheEntry),
+			inflight: make(map[string]*cacheCall),
+			gen:      make(map[CacheResource]uint64),
+		}
+	}
+}
+
+// InvalidateCache drops cached responses for the given resources, or for all resources when none are given.
+// It is a no-op when caching is not enabled.
+func (c *Client) InvalidateCache(resources ...CacheResource) {
+	if c.cache != nil {
+		c.cache.invalidate(resources...)
+	}
+}
+
+// cacheResourceFor maps a GET path (with optional query) to its cache resource.
+func cacheResourceFor(path string) (CacheResource, bool) {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	switch {
+	case path == "/lists":
+		return CacheLists, true
+	case path == "/contacts/properties":
+		return CacheContactProperties, true
+	case path == "/themes":
+		return CacheThemes, true
+	case strings.HasPrefix(path, "/themes/"):
+		return CacheTheme, true
+	case path == "/components":
+		return CacheComponents, true
+	case path == "/transactional":
+		return CacheTransactionals, true
+	}
+	return "", false
+}
+
+// cacheInvalidations lists the resources a successful non-GET request to a path changes.
+var cacheInvalidations = map[string][]CacheResource{
+	"/contacts/properties": {CacheContactProperties},
+}
+
+// fetch is send with the read-through cache in front of it (when configured).
+func (c *Client) fetch(ctx context.Context, method, path string, body []byte, opts *doOpts) ([]byte, error) {
+	if c.cache == nil {
+		return c.send(ctx, method, path, body, opts)
+	}
+	if method != http.MethodGet {
+		slurp, err := c.send(ctx, method, path, body, opts)
+		if err == nil {
+			if i := strings.IndexByte(path, '?'); i >= 0 {
+				path = path[:i]
+			}
+			c.cache.invalidate(cacheInvalidations[path]...)
+		}
+		return slurp, err
+	}
+	res, ok := cacheResourceFor(path)
+	if !ok || c.cache.cfg.ttl(res) <= 0 {
+		return c.send(ctx, method, path, body, opts)
+	}
+	return c.cache.get(ctx, res, path, func(ctx context.Context) ([]byte, error) {
+		return c.send(ctx, method, path, body, opts)
+	})
+}
+
+// cacheLoadTimeout bounds a shared fetch, which runs detached from the cancellation of the caller that started it.
+const cacheLoadTimeout = 2 * time.Minute
+
+type cacheEntry struct {
+	resource CacheResource
+	body     []byte
+	expires  time.Time
+}
+
+// cacheCall is an in-flight fetch shared by concurrent callers of the same key.
+type cacheCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+type responseCache struct {
+	cfg CacheConfig
+
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	inflight map[string]*cacheCall
+	gen      map[CacheResource]uint64 // bumped on invalidation so in-flight fetches don't store stale data
+}
+
+// get returns the cached body for key or starts a shared load. The load runs with ctx's values but not its
+// cancellation, so one caller giving up does not fail the others; each caller waits only as long as its own ctx.
+func (rc *responseCache) get(ctx context.Context, res CacheResource, key string, load func(context.Context) ([]byte, error)) ([]byte, error) {
+	rc.mu.Lock()
+	if e, ok := rc.entries[key]; ok && time.Now().Before(e.expires) {
+		rc.mu.Unlock()
+		return e.body, nil
+	}
+	call, ok := rc.inflight[key]
+	if !ok {
+		call = &cacheCall{done: make(chan struct{})}
+		rc.inflight[key] = call
+		go rc.load(context.WithoutCancel(ctx), res, key, rc.gen[res], call, load)
+	}
+	rc.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.body, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load runs a shared fetch and stores its result unless the resource was invalidated meanwhile.
+func (rc *responseCache) load(ctx context.Context, res CacheResource, key string, gen uint64, call *cacheCall, load func(context.Context) ([]byte, error)) {
+	ctx, cancel := context.WithTimeout(ctx, cacheLoadTimeout)
+	defer cancel()
+	call.body, call.err = load(ctx)
+
+	rc.mu.Lock()
+	delete(rc.inflight, key)
+	if call.err == nil && rc.gen[res] == gen {
+		now := time.Now()
+		for k, e := range rc.entries {
+			if !now.Before(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		rc.entries[key] = cacheEntry{resource: res, body: call.body, expires: now.Add(rc.cfg.ttl(res))}
+	}
+	rc.mu.Unlock()
+	close(call.done)
+}
+
+func (rc *responseCache) invalidate(resources ...CacheResource) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(resources) == 0 {
+		rc.entries = make(map[string]cacheEntry)
+		for _, r := range []CacheResource{CacheLists, CacheContactProperties, CacheThemes, CacheTheme, CacheComponents, CacheTransactionals} {
+			rc.gen[r]++
+		}
+		return
+	}
+	for _, r := range resources {
+		rc.gen[r]++
+	}
+	for k, e := range rc.entries {
+		for _, r := range resources {
+			if e.resource == r {
+				delete(rc.entries, k)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Cache_ReadThroughAndCopies(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(`[{"id":"list_1","name":"Main","description":"","isPublic":true}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", WithBaseURL(server.URL), WithCache(DefaultCacheConfig()))
+	ctx := context.Background()
+	first, err := client.GetLists(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first[0].Name = "mutated by caller"
+	second, err := client.GetLists(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second[0].Name != "Main" {
+		t.Errorf("cached value was shared with a previous caller: %+v", second)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("server hits: got %d, want 1", n)
+	}
+
+	client.InvalidateCache(CacheLists)
+	if _, err := client.GetLists(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("server hits after invalidate: got %d, want 2", n)
+	}
+}
+
+func TestClient_Cache_DeduplicatesConcurrentFetches(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write([]byte(`{"success":true,"themeId":"th_1","name":"Brand"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", WithBaseURL(server.URL), WithCache(CacheConfig{Theme: time.Minute}))
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := client.GetTheme(ctx, "th_1")
+			if err != nil || got.Name != "Brand" {
+				t.Errorf("got %+v, %v", got, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("server hits: got %d, want 1", n)
+	}
+}
+
+func TestClient_Cache_SharedFetchOutlivesFirstCaller(t *testing.T) {
+	var hits int32
+	started, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Write([]byte(`{"success":true,"themeId":"th_1","name":"Brand"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", WithBaseURL(server.URL), WithCache(CacheConfig{Theme: time.Minute}))
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.GetTheme(ctx, "th_1")
+		firstErr <- err
+	}()
+	<-started
+	second := make(chan error, 1)
+	go func() {
+		got, err := client.GetTheme(context.Background(), "th_1")
+		if err == nil && got.Name != "Brand" {
+			t.Errorf("second caller got %+v", got)
+		}
+		second <- err
+	}()
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller: %v", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("second caller failed after the first cancelled: %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("server hits: got %d, want 1", n)
+	}
+}
+
+func TestClient_Cache_MutationInvalidates(t *testing.T) {
+	var listHits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"success":true}`))
+			return
+		}
+		atomic.AddInt32(&listHits, 1)
+		w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", WithBaseURL(server.URL), WithCache(DefaultCacheConfig()))
+	ctx := context.Background()
+	client.ListContactProperties(ctx, "custom")
+	client.ListContactProperties(ctx, "custom")
+	if n := atomic.LoadInt32(&listHits); n != 1 {
+		t.Fatalf("list hits: got %d, want 1", n)
+	}
+	if _, err := client.CreateContactProperty(ctx, &ContactPropertyCreateRequest{Name: "plan", Type: "string"}); err != nil {
+		t.Fatal(err)
+	}
+	client.ListContactProperties(ctx, "custom")
+	if n := atomic.LoadInt32(&listHits); n != 2 {
+		t.Errorf("list hits after CreateContactProperty: got %d, want 2", n)
+	}
+}
+
+func TestClient_Cache_UncachedResourcesAndErrors(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", WithBaseURL(server.URL), WithCache(CacheConfig{Lists: time.Minute}))
+	ctx := context.Background()
+	client.GetLists(ctx)
+	client.GetLists(ctx)
+	client.ListComponents(ctx, 0, "") // zero TTL: not cached
+	client.ListComponents(ctx, 0, "")
+	if n := atomic.LoadInt32(&hits); n != 4 {
+		t.Errorf("server hits: got %d, want 4 (errors and zero-TTL resources are not cached)", n)
+	}
+}
@@ -0,0 +1,263 @@
+package loops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Whats-A-MattR/loops-go-sdk/internal/atomicfile"
+)
+
+// DefaultCampaignWatchInterval is the polling interval used when CampaignWatchOptions.Interval is zero.
+const DefaultCampaignWatchInterval = time.Minute
+
+// CampaignEventType is the kind of a CampaignEvent.
+type CampaignEventType string
+
+const (
+	CampaignCreated        CampaignEventType = "created"
+	CampaignRenamed        CampaignEventType = "renamed"
+	CampaignStatusChanged  CampaignEventType = "status-changed"
+	CampaignContentUpdated CampaignEventType = "content-updated" // updatedAt moved, name and status unchanged
+	CampaignRemoved        CampaignEventType = "removed"         // no longer listed
+)
+
+// CampaignEvent is a change to one campaign seen between two polls. Campaign is the campaign as listed; for
+// CampaignRemoved only its ID, name, status and updatedAt (as last seen) are set.
+type CampaignEvent struct {
+	Type      CampaignEventType `json:"type"`
+	Campaign  CampaignListItem  `json:"campaign"`
+	OldName   string            `json:"oldName,omitempty"`   // CampaignRenamed
+	OldStatus CampaignStatus    `json:"oldStatus,omitempty"` // CampaignStatusChanged
+}
+
+// CampaignWatchOptions configures a CampaignWatcher.
+type CampaignWatchOptions struct {
+	// Interval is the time between polls. Default DefaultCampaignWatchInterval.
+	Interval time.Duration
+	// CheckpointPath, if set, persists the last seen state of every campaign so a restarted watcher only reports
+	// what changed while it was stopped.
+	CheckpointPath string
+	// EmitExisting reports every campaign as created on the first poll when there is no checkpoint. By default
+	// the first poll only records a baseline.
+	EmitExisting bool
+	// OnError, if set, receives errors from listing campaigns and Run keeps polling. By default Run returns them.
+	OnError func(error)
+}
+
+// CampaignWatcher polls ListCampaigns and reports campaigns that were created, renamed, changed status (for
+// example Draft to Scheduled or Sent), had their content updated, or were removed. It is not safe for
+// concurrent use.
+type CampaignWatcher struct {
+	client   *Client
+	opts     CampaignWatchOptions
+	state    map[string]campaignSnapshot // campaign ID -> last delivered state
+	baseline bool                        // the next poll records state without events
+}
+
+// campaignCheckpoint is the on-disk state of a CampaignWatcher.
+type campaignCheckpoint struct {
+	Campaigns map[string]campaignSnapshot `json:"campaigns"`
+}
+
+type campaignSnapshot struct {
+	Name      string         `json:"name"`
+	Status    CampaignStatus `json:"status"`
+	UpdatedAt Timestamp      `json:"updatedAt"`
+}
+
+// NewCampaignWatcher returns a watcher, loading opts.CheckpointPath if it exists.
+func (c *Client) NewCampaignWatcher(opts CampaignWatchOptions) (*CampaignWatcher, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultCampaignWatchInterval
+	}
+	w := &CampaignWatcher{client: c, opts: opts, state: make(map[string]campaignSnapshot), baseline: !opts.EmitExisting}
+	if opts.CheckpointPath == "" {
+		return w, nil
+	}
+	b, err := os.ReadFile(opts.CheckpointPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loops: read campaign checkpoint: %w", err)
+	}
+	var cp campaignCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("loops: parse campaign checkpoint %s: %w", opts.CheckpointPath, err)
+	}
+	if cp.Campaigns != nil {
+		w.state = cp.Campaigns
+	}
+	w.baseline = false
+	return w, nil
+}
+
+// Poll lists every campaign once and returns the changes since the previous poll (or the checkpoint). The
+// returned events count as delivered: they are recorded and the checkpoint is saved.
+func (w *CampaignWatcher) Poll(ctx context.Context) ([]CampaignEvent, error) {
+	events, err := w.changes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return events, w.deliver(events, nil)
+}
+
+// Run polls every Interval, starting immediately, and calls handle for each event in order. An event is
+// recorded in the checkpoint only after handle returns nil, so a failed event is reported again after a
+// restart. Run returns handle's error, a checkpoint error, a listing error (unless OnError is set) or
+// ctx.Err() when ctx is done.
+func (w *CampaignWatcher) Run(ctx context.Context, handle func(CampaignEvent) error) error {
+	for {
+		events, err := w.changes(ctx)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && w.opts.OnError == nil:
+			return err
+		case err != nil:
+			w.opts.OnError(err)
+		default:
+			if err := w.deliver(events, handle); err != nil {
+				return err
+			}
+		}
+		t := time.NewTimer(w.opts.Interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Events runs the watcher in a goroutine and sends each event on the returned channel; an event is recorded
+// once it has been received. When Run stops, its error is sent on the error channel and both channels are closed.
+func (w *CampaignWatcher) Events(ctx context.Context) (<-chan CampaignEvent, <-chan error) {
+	events, errc := make(chan CampaignEvent), make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(events)
+		errc <- w.Run(ctx, func(ev CampaignEvent) error {
+			select {
+			case events <- ev:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return events, errc
+}
+
+// changes lists every campaign and compares it with the recorded state. On a baseline poll it records the
+// state and returns no events.
+func (w *CampaignWatcher) changes(ctx context.Context) ([]CampaignEvent, error) {
+	var campaigns []CampaignListItem
+	cursor := ""
+	for {
+		page, err := w.client.ListCampaigns(ctx, 50, cursor)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, page.Data...)
+		if page.Pagination.NextCursor == nil || *page.Pagination.NextCursor == "" {
+			break
+		}
+		cursor = *page.Pagination.NextCursor
+	}
+
+	if w.baseline {
+		w.baseline = false
+		for _, c := range campaigns {
+			w.state[c.CampaignID] = campaignSnapshot{Name: c.Name, Status: c.Status, UpdatedAt: c.UpdatedAt}
+		}
+		return nil, w.save()
+	}
+
+	var events []CampaignEvent
+	listed := make(map[string]bool, len(campaigns))
+	for _, c := range campaigns {
+		listed[c.CampaignID] = true
+		prev, ok := w.state[c.CampaignID]
+		switch {
+		case !ok:
+			events = append(events, CampaignEvent{Type: CampaignCreated, Campaign: c})
+			continue
+		case prev.Name == c.Name && prev.Status == c.Status:
+			if !prev.UpdatedAt.Equal(c.UpdatedAt) {
+				events = append(events, CampaignEvent{Type: CampaignContentUpdated, Campaign: c})
+			}
+			continue
+		}
+		if prev.Name != c.Name {
+			events = append(events, CampaignEvent{Type: CampaignRenamed, Campaign: c, OldName: prev.Name})
+		}
+		if prev.Status != c.Status {
+			events = append(events, CampaignEvent{Type: CampaignStatusChanged, Campaign: c, OldStatus: prev.Status})
+		}
+	}
+	var removed []string
+	for id := range w.state {
+		if !listed[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		prev := w.state[id]
+		events = append(events, CampaignEvent{Type: CampaignRemoved,
+			Campaign: CampaignListItem{CampaignID: id, Name: prev.Name, Status: prev.Status, UpdatedAt: prev.UpdatedAt}})
+	}
+	return events, nil
+}
+
+// deliver passes events to handle (if not nil), records each one that succeeds and saves the checkpoint.
+// Each event only records the fields it reports, so a failed status change after a rename is reported again.
+func (w *CampaignWatcher) deliver(events []CampaignEvent, handle func(CampaignEvent) error) error {
+	for _, ev := range events {
+		if handle != nil {
+			if err := handle(ev); err != nil {
+				if saveErr := w.save(); saveErr != nil {
+					return errors.Join(err, saveErr)
+				}
+				return err
+			}
+		}
+		id := ev.Campaign.CampaignID
+		snap := w.state[id]
+		switch ev.Type {
+		case CampaignRemoved:
+			delete(w.state, id)
+			continue
+		case CampaignCreated:
+			snap = campaignSnapshot{Name: ev.Campaign.Name, Status: ev.Campaign.Status}
+		case CampaignRenamed:
+			snap.Name = ev.Campaign.Name
+		case CampaignStatusChanged:
+			snap.Status = ev.Campaign.Status
+		}
+		snap.UpdatedAt = ev.Campaign.UpdatedAt
+		w.state[id] = snap
+	}
+	return w.save()
+}
+
+func (w *CampaignWatcher) save() error {
+	if w.opts.CheckpointPath == "" {
+		return nil
+	}
+	b, err := json.Marshal(campaignCheckpoint{Campaigns: w.state})
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(w.opts.CheckpointPath, b, 0o600); err != nil {
+		return fmt.Errorf("loops: write campaign checkpoint: %w", err)
+	}
+	return nil
+}
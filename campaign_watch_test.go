@@ -0,0 +1,167 @@
+package loops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// campaignsServer serves the given campaigns from GET /campaigns, one per page. set replaces them.
+func campaignsServer(t *testing.T) (*Client, func(...CampaignListItem)) {
+	t.Helper()
+	var mu sync.Mutex
+	var campaigns []CampaignListItem
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		start := 0
+		fmt.Sscan(r.URL.Query().Get("cursor"), &start)
+		out := ListCampaignsResponse{Success: true, Data: []CampaignListItem{}}
+		if start < len(campaigns) {
+			out.Data = append(out.Data, campaigns[start])
+		}
+		if start+1 < len(campaigns) {
+			next := fmt.Sprint(start + 1)
+			out.Pagination.NextCursor = &next
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("key", WithBaseURL(server.URL)), func(items ...CampaignListItem) {
+		mu.Lock()
+		defer mu.Unlock()
+		campaigns = items
+	}
+}
+
+func eventStrings(events []CampaignEvent) string {
+	var s []string
+	for _, ev := range events {
+		s = append(s, fmt.Sprintf("%s %s %s/%s", ev.Type, ev.Campaign.CampaignID, ev.Campaign.Name, ev.Campaign.Status))
+	}
+	return strings.Join(s, "; ")
+}
+
+func TestCampaignWatcher_Poll(t *testing.T) {
+	client, set := campaignsServer(t)
+	checkpoint := filepath.Join(t.TempDir(), "campaigns.json")
+	ctx := context.Background()
+	set(CampaignListItem{CampaignID: "c1", Name: "Launch", Status: "Draft", UpdatedAt: timestampOf("1")},
+		CampaignListItem{CampaignID: "c2", Name: "Old", Status: "Sent", UpdatedAt: timestampOf("1")})
+
+	w, err := client.NewCampaignWatcher(CampaignWatchOptions{CheckpointPath: checkpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events, err := w.Poll(ctx); err != nil || len(events) != 0 {
+		t.Fatalf("baseline: %v, %v", events, err)
+	}
+
+	set(CampaignListItem{CampaignID: "c1", Name: "Launch!", Status: "Scheduled", UpdatedAt: timestampOf("2")},
+		CampaignListItem{CampaignID: "c3", Name: "New", Status: "Draft", UpdatedAt: timestampOf("2")})
+	events, err := w.Poll(ctx)
+	want := "renamed c1 Launch!/Scheduled; status-changed c1 Launch!/Scheduled; created c3 New/Draft; removed c2 Old/Sent"
+	if err != nil || eventStrings(events) != want {
+		t.Fatalf("got %q, %v", eventStrings(events), err)
+	}
+	if events[0].OldName != "Launch" || events[1].OldStatus != "Draft" {
+		t.Errorf("old values: %+v %+v", events[0], events[1])
+	}
+
+	// A restarted watcher resumes from the checkpoint.
+	set(CampaignListItem{CampaignID: "c1", Name: "Launch!", Status: "Scheduled", UpdatedAt: timestampOf("3")},
+		CampaignListItem{CampaignID: "c3", Name: "New", Status: "Draft", UpdatedAt: timestampOf("2")})
+	w, err = client.NewCampaignWatcher(CampaignWatchOptions{CheckpointPath: checkpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err = w.Poll(ctx)
+	if err != nil || eventStrings(events) != "content-updated c1 Launch!/Scheduled" {
+		t.Fatalf("after restart: %q, %v", eventStrings(events), err)
+	}
+}
+
+func TestCampaignWatcher_EmitExisting(t *testing.T) {
+	client, set := campaignsServer(t)
+	set(CampaignListItem{CampaignID: "c1", Name: "Launch", Status: "Draft"})
+	w, err := client.NewCampaignWatcher(CampaignWatchOptions{EmitExisting: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := w.Poll(context.Background())
+	if err != nil || eventStrings(events) != "created c1 Launch/Draft" {
+		t.Fatalf("got %q, %v", eventStrings(events), err)
+	}
+}
+
+func TestCampaignWatcher_RunRedeliversFailedEvents(t *testing.T) {
+	client, set := campaignsServer(t)
+	checkpoint := filepath.Join(t.TempDir(), "campaigns.json")
+	set(CampaignListItem{CampaignID: "c1", Name: "Launch", Status: "Draft", UpdatedAt: timestampOf("1")})
+	w, _ := client.NewCampaignWatcher(CampaignWatchOptions{CheckpointPath: checkpoint})
+	if _, err := w.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	set(CampaignListItem{CampaignID: "c1", Name: "Launch!", Status: "Sent", UpdatedAt: timestampOf("2")})
+	boom := errors.New("boom")
+	err := w.Run(context.Background(), func(ev CampaignEvent) error {
+		if ev.Type == CampaignStatusChanged {
+			return boom
+		}
+		return nil
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Only the status change is reported again.
+	w, _ = client.NewCampaignWatcher(CampaignWatchOptions{CheckpointPath: checkpoint, Interval: time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, errc := w.Events(ctx)
+	ev := <-events
+	if ev.Type != CampaignStatusChanged || ev.OldStatus != "Draft" {
+		t.Errorf("redelivered %+v", ev)
+	}
+	cancel()
+	for range events {
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Events error: %v", err)
+	}
+}
+
+func TestCampaignWatcher_OnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"success":false,"message":"bad"}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient("key", WithBaseURL(server.URL))
+
+	w, _ := client.NewCampaignWatcher(CampaignWatchOptions{})
+	var apiErr *APIError
+	if err := w.Run(context.Background(), nil); !errors.As(err, &apiErr) {
+		t.Fatalf("without OnError: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := 0
+	w, _ = client.NewCampaignWatcher(CampaignWatchOptions{Interval: time.Millisecond, OnError: func(error) {
+		if errs++; errs == 3 {
+			cancel()
+		}
+	}})
+	if err := w.Run(ctx, nil); !errors.Is(err, context.Canceled) || errs != 3 {
+		t.Errorf("with OnError: %v after %d errors", err, errs)
+	}
+}
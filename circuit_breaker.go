@@ -0,0 +1,226 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts consecutive failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast with ErrCircuitOpen until OpenTimeout elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through to probe recovery.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreakerConfig configures the optional circuit breaker (see WithCircuitBreaker).
+// Zero values use the defaults noted on each field.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures (5xx responses, timeouts or transport errors) that
+	// opens the circuit. Default 5.
+	FailureThreshold int
+	// OpenTimeout is how long an open circuit fails fast before moving to half-open. Default 30s.
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests caps concurrent trial requests while half-open. Default 1.
+	HalfOpenMaxRequests int
+	// OnStateChange is called after every state transition with the endpoint group (e.g. "contacts", "transactional").
+	// It runs synchronously on the request goroutine and must not block.
+	OnStateChange func(group string, from, to CircuitState)
+}
+
+// WithCircuitBreaker enables a circuit breaker per endpoint group. The group is the first path segment of the
+// endpoint ("contacts", "transactional", "events", "campaigns", ...), so an outage on one group does not block others.
+// While a group's circuit is open, requests fail immediately with an error matching ErrCircuitOpen.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) ClientOption {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenMaxRequests <= 0 {
+		cfg.HalfOpenMaxRequests = 1
+	}
+	return func(c *Client) {
+		c.breakers = &breakerSet{cfg: cfg, now: time.Now, m: make(map[string]*circuitBreaker)}
+	}
+}
+
+// CircuitState returns the current circuit state for an endpoint group. It reports CircuitClosed when no
+// circuit breaker is configured or the group has not been used yet.
+func (c *Client) CircuitState(group string) CircuitState {
+	if c.breakers == nil {
+		return CircuitClosed
+	}
+	return c.breakers.get(group).currentState()
+}
+
+// endpointGroup returns the circuit breaker group for a request path: its first segment, without query.
+func endpointGroup(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexAny(path, "/?"); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
+
+type breakerSet struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu sync.Mutex
+	m  map[string]*circuitBreaker
+}
+
+func (s *breakerSet) get(group string) *circuitBreaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.m[group]
+	if !ok {
+		b = &circuitBreaker{group: group, set: s}
+		s.m[group] = b
+	}
+	return b
+}
+
+type circuitBreaker struct {
+	group string
+	set   *breakerSet
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	inFlight int // trial requests while half-open
+}
+
+// breakerOutcome classifies a finished request for the breaker.
+type breakerOutcome int
+
+const (
+	outcomeSuccess breakerOutcome = iota
+	outcomeFailure
+	outcomeIgnored // e.g. caller cancelled; says nothing about API health
+)
+
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.set.now().Sub(b.openedAt) >= b.set.cfg.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a request may proceed, returning an ErrCircuitOpen error if not.
+// trial is true when the request is a half-open probe and must be passed back to record.
+func (b *circuitBreaker) allow() (trial bool, err error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == CircuitOpen {
+		if b.set.now().Sub(b.openedAt) < b.set.cfg.OpenTimeout {
+			b.mu.Unlock()
+			return false, fmt.Errorf("%w: %s", ErrCircuitOpen, b.group)
+		}
+		b.state = CircuitHalfOpen
+		b.inFlight = 0
+	}
+	if b.state == CircuitHalfOpen {
+		if b.inFlight >= b.set.cfg.HalfOpenMaxRequests {
+			b.mu.Unlock()
+			return false, fmt.Errorf("%w: %s", ErrCircuitOpen, b.group)
+		}
+		b.inFlight++
+		trial = true
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return trial, nil
+}
+
+// record updates the breaker with the outcome of a request admitted by allow. Outcomes of non-trial
+// requests that finish after the circuit has left the closed state are ignored.
+func (b *circuitBreaker) record(o breakerOutcome, trial bool) {
+	b.mu.Lock()
+	from := b.state
+	switch {
+	case trial && b.state == CircuitHalfOpen:
+		b.inFlight--
+		switch o {
+		case outcomeSuccess:
+			b.state = CircuitClosed
+			b.failures = 0
+		case outcomeFailure:
+			b.state = CircuitOpen
+			b.openedAt = b.set.now()
+		}
+	case !trial && b.state == CircuitClosed:
+		switch o {
+		case outcomeSuccess:
+			b.failures = 0
+		case outcomeFailure:
+			b.failures++
+			if b.failures >= b.set.cfg.FailureThreshold {
+				b.state = CircuitOpen
+				b.openedAt = b.set.now()
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.set.cfg.OnStateChange != nil {
+		b.set.cfg.OnStateChange(b.group, from, to)
+	}
+}
+
+// classifyOutcome treats 5xx responses, timeouts and transport errors as failures. Other API errors (4xx)
+// mean the API is up and count as successes; caller cancellation is ignored.
+func classifyOutcome(err error) breakerOutcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode >= 500 {
+			return outcomeFailure
+		}
+		return outcomeSuccess
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return outcomeFailure
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return outcomeFailure
+	}
+	if errors.Is(err, context.Canceled) {
+		return outcomeIgnored
+	}
+	return outcomeFailure
+}
@@ -0,0 +1,136 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	var transitions []string
+	client := NewClient("key", WithBaseURL(server.URL), WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Hour,
+		OnStateChange: func(group string, from, to CircuitState) {
+			transitions = append(transitions, group+":"+from.String()+"->"+to.String())
+		},
+	}))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := client.FindContact(ctx, "a@b.com", "")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("call %d: expected 503 APIError, got %v", i, err)
+		}
+	}
+	_, err := client.FindContact(ctx, "a@b.com", "")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("server hits: got %d, want 3 (open circuit must not send)", got)
+	}
+	if got := client.CircuitState("contacts"); got != CircuitOpen {
+		t.Errorf("contacts state: got %s, want open", got)
+	}
+	if len(transitions) != 1 || transitions[0] != "contacts:closed->open" {
+		t.Errorf("transitions: %v", transitions)
+	}
+
+	// Other endpoint groups have their own breaker.
+	_, err = client.SendTransactional(ctx, &TransactionalRequest{Email: "a@b.com", TransactionalID: "tx"}, "")
+	if errors.Is(err, ErrCircuitOpen) {
+		t.Error("transactional group should not be affected by the contacts breaker")
+	}
+}
+
+func TestClient_CircuitBreaker_HalfOpenRecovers(t *testing.T) {
+	var fail int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"teamName":"Acme"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", WithBaseURL(server.URL), WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+	}))
+	now := time.Now()
+	client.breakers.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := client.GetAPIKey(ctx); err == nil {
+		t.Fatal("expected 500 error")
+	}
+	if _, err := client.GetAPIKey(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// After the open timeout a failing trial re-opens the circuit.
+	now = now.Add(time.Minute)
+	if got := client.CircuitState("api-key"); got != CircuitHalfOpen {
+		t.Errorf("state: got %s, want half-open", got)
+	}
+	if _, err := client.GetAPIKey(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected trial request to reach server, got %v", err)
+	}
+	if got := client.CircuitState("api-key"); got != CircuitOpen {
+		t.Errorf("state after failed trial: got %s, want open", got)
+	}
+
+	// A successful trial closes it.
+	atomic.StoreInt32(&fail, 0)
+	now = now.Add(time.Minute)
+	if _, err := client.GetAPIKey(ctx); err != nil {
+		t.Fatalf("trial request: %v", err)
+	}
+	if got := client.CircuitState("api-key"); got != CircuitClosed {
+		t.Errorf("state after successful trial: got %s, want closed", got)
+	}
+}
+
+func TestClient_CircuitBreaker_ClientErrorsDoNotTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient("key", WithBaseURL(server.URL), WithCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1}))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetCampaign(ctx, "c1"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: 4xx responses must not open the circuit", i)
+		}
+	}
+}
+
+func TestEndpointGroup(t *testing.T) {
+	tests := map[string]string{
+		"/contacts/find?email=a":   "contacts",
+		"/contacts/create":         "contacts",
+		"/transactional":           "transactional",
+		"/transactional?perPage=2": "transactional",
+		"/email-messages/em_1":     "email-messages",
+		"/api-key":                 "api-key",
+	}
+	for path, want := range tests {
+		if got := endpointGroup(path); got != want {
+			t.Errorf("endpointGroup(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -13,9 +13,13 @@ import (
 
 // Client is the Loops API client. All methods are safe for concurrent use.
 type Client struct {
-	apiKey  string
-	baseURL string
-	client  *http.Client
+	apiKey   string
+	baseURL  string
+	client   *http.Client
+	breakers *breakerSet
+	limiter  *rateLimiter
+	creds    *credentialSource
+	cache    *responseCache
 }
 
 // ClientOption configures a Client.
@@ -68,15 +72,54 @@ func mergeBody(v interface{}, extra map[string]interface{}) ([]byte, error) {
 }
 
 func (c *Client) do(ctx context.Context, method, path string, body []byte, result interface{}, opts *doOpts) error {
+	slurp, err := c.fetch(ctx, method, path, body, opts)
+	if err != nil {
+		return err
+	}
+	if result != nil && len(slurp) > 0 {
+		if err := json.Unmarshal(slurp, result); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// send performs the request through the rate limiter and circuit breaker (when configured) and returns the response body.
+func (c *Client) send(ctx context.Context, method, path string, body []byte, opts *doOpts) ([]byte, error) {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	// The key is resolved before the breaker so a credential provider failure is not counted against the endpoint.
+	key, err := c.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.breakers == nil {
+		return c.authorizedRoundTrip(ctx, method, path, key, body, opts)
+	}
+	breaker := c.breakers.get(endpointGroup(path))
+	trial, err := breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	slurp, err := c.authorizedRoundTrip(ctx, method, path, key, body, opts)
+	breaker.record(classifyOutcome(err), trial)
+	return slurp, err
+}
+
+// roundTrip sends a single HTTP request and returns the response body, or an *APIError for status >= 400.
+func (c *Client) roundTrip(ctx context.Context, method, path, apiKey string, body []byte, opts *doOpts) ([]byte, error) {
 	var bodyReader io.Reader
 	if len(body) > 0 {
 		bodyReader = bytes.NewReader(body)
 	}
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("Content-Type", "application/json")
 	if opts != nil {
 		for k, v := range opts.headers {
@@ -85,22 +128,17 @@ func (c *Client) do(ctx context.Context, method, path string, body []byte, resul
 	}
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	slurp, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if resp.StatusCode >= 400 {
-		return parseErrorBody(resp, slurp)
-	}
-	if result != nil && len(slurp) > 0 {
-		if err := json.Unmarshal(slurp, result); err != nil {
-			return fmt.Errorf("decode response: %w", err)
-		}
+		return nil, parseErrorBody(resp, slurp)
 	}
-	return nil
+	return slurp, nil
 }
 
 type doOpts struct {
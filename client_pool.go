@@ -0,0 +1,189 @@
+package loops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the Loops API key for a tenant (one Loops team per tenant).
+type CredentialProvider interface {
+	APIKey(ctx context.Context, tenant string) (string, error)
+}
+
+// CredentialProviderFunc adapts a function to CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context, tenant string) (string, error)
+
+// APIKey calls f(ctx, tenant).
+func (f CredentialProviderFunc) APIKey(ctx context.Context, tenant string) (string, error) {
+	return f(ctx, tenant)
+}
+
+// ClientPool lazily creates and caches one *Client per tenant. All clients share a single *http.Client
+// (and therefore its transport and connection pool). A tenant's key is verified with GetAPIKey before
+// its client is first returned; afterwards the client keeps consulting the provider (see WithCredentialProvider),
+// so rotated keys are picked up without evicting the tenant. ClientPool is safe for concurrent use.
+type ClientPool struct {
+	provider   CredentialProvider
+	httpClient *http.Client
+	clientOpts []ClientOption
+	rateLimit  poolRateLimit
+	tenantRate map[string]poolRateLimit
+
+	mu      sync.Mutex
+	tenants map[string]*poolEntry
+}
+
+type poolRateLimit struct {
+	rps   float64
+	burst int
+	set   bool
+}
+
+type poolEntry struct {
+	ready    chan struct{} // closed once client/teamName/err are set
+	client   *Client
+	teamName string
+	err      error
+}
+
+// PoolOption configures a ClientPool.
+type PoolOption func(*ClientPool)
+
+// WithPoolHTTPClient sets the *http.Client shared by every tenant's client (default: http.DefaultClient).
+func WithPoolHTTPClient(client *http.Client) PoolOption {
+	return func(p *ClientPool) {
+		p.httpClient = client
+	}
+}
+
+// WithPoolClientOptions adds options applied to every tenant's client (e.g. WithBaseURL, WithCircuitBreaker).
+// A WithRateLimit given here applies unless WithPoolRateLimit or WithTenantRateLimit sets a limit, which wins.
+func WithPoolClientOptions(opts ...ClientOption) PoolOption {
+	return func(p *ClientPool) {
+		p.clientOpts = append(p.clientOpts, opts...)
+	}
+}
+
+// WithPoolRateLimit sets the default per-tenant rate limit (see WithRateLimit). Each tenant gets its own bucket.
+// It takes precedence over a WithRateLimit passed to WithPoolClientOptions.
+func WithPoolRateLimit(rps float64, burst int) PoolOption {
+	return func(p *ClientPool) {
+		p.rateLimit = poolRateLimit{rps: rps, burst: burst, set: true}
+	}
+}
+
+// WithTenantRateLimit overrides the rate limit for a single tenant. A zero rps removes the limit for that tenant.
+func WithTenantRateLimit(tenant string, rps float64, burst int) PoolOption {
+	return func(p *ClientPool) {
+		p.tenantRate[tenant] = poolRateLimit{rps: rps, burst: burst, set: true}
+	}
+}
+
+// NewClientPool returns a pool that resolves tenant API keys through provider.
+func NewClientPool(provider CredentialProvider, opts ...PoolOption) *ClientPool {
+	p := &ClientPool{
+		provider:   provider,
+		httpClient: http.DefaultClient,
+		tenantRate: make(map[string]poolRateLimit),
+		tenants:    make(map[string]*poolEntry),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// poolVerifyTimeout bounds a tenant's first verification, which runs detached from the cancellation of the
+// caller that started it.
+const poolVerifyTimeout = 2 * time.Minute
+
+// Client returns the client for tenant, creating and verifying it on first use. Concurrent first calls for
+// the same tenant share one verification, which keeps running if the caller that started it gives up; each
+// caller waits only as long as its own ctx. Failures are not cached, so a later call retries.
+func (p *ClientPool) Client(ctx context.Context, tenant string) (*Client, error) {
+	p.mu.Lock()
+	e, ok := p.tenants[tenant]
+	if !ok {
+		e = &poolEntry{ready: make(chan struct{})}
+		p.tenants[tenant] = e
+		go p.verify(context.WithoutCancel(ctx), tenant, e)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-e.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.client, nil
+}
+
+// verify connects tenant and publishes the result in e, dropping e from the pool if it failed.
+func (p *ClientPool) verify(ctx context.Context, tenant string, e *poolEntry) {
+	ctx, cancel := context.WithTimeout(ctx, poolVerifyTimeout)
+	defer cancel()
+	e.client, e.teamName, e.err = p.connect(ctx, tenant)
+	if e.err != nil {
+		p.mu.Lock()
+		if p.tenants[tenant] == e {
+			delete(p.tenants, tenant)
+		}
+		p.mu.Unlock()
+	}
+	close(e.ready)
+}
+
+// connect builds tenant's client and verifies its key. The client sources its key from the provider only,
+// so verification and later requests share the client's credential cache.
+func (p *ClientPool) connect(ctx context.Context, tenant string) (*Client, string, error) {
+	provider := func(ctx context.Context) (string, error) {
+		return p.provider.APIKey(ctx, tenant)
+	}
+	rl, ok := p.tenantRate[tenant]
+	if !ok {
+		rl = p.rateLimit
+	}
+	opts := make([]ClientOption, 0, len(p.clientOpts)+3)
+	opts = append(opts, WithHTTPClient(p.httpClient))
+	opts = append(opts, p.clientOpts...)
+	if rl.set {
+		opts = append(opts, WithRateLimit(rl.rps, rl.burst))
+	}
+	opts = append(opts, WithCredentialProvider(provider))
+	client := NewClient("", opts...)
+	resp, err := client.GetAPIKey(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("loops: verify API key for tenant %q: %w", tenant, err)
+	}
+	return client, resp.TeamName, nil
+}
+
+// TeamName returns the Loops team name reported by GetAPIKey when tenant's client was verified.
+// ok is false if the tenant has no verified client yet.
+func (p *ClientPool) TeamName(tenant string) (name string, ok bool) {
+	p.mu.Lock()
+	e, found := p.tenants[tenant]
+	p.mu.Unlock()
+	if !found {
+		return "", false
+	}
+	select {
+	case <-e.ready:
+		return e.teamName, e.err == nil
+	default:
+		return "", false
+	}
+}
+
+// Evict drops the cached client for tenant so the next Client call re-fetches and re-verifies its key.
+func (p *ClientPool) Evict(tenant string) {
+	p.mu.Lock()
+	delete(p.tenants, tenant)
+	p.mu.Unlock()
+}
@@ -0,0 +1,195 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientPool_CreatesVerifiesAndCachesPerTenant(t *testing.T) {
+	var verifications int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api-key" {
+			atomic.AddInt32(&verifications, 1)
+		}
+		switch r.Header.Get("Authorization") {
+		case "Bearer key-a":
+			w.Write([]byte(`{"success":true,"teamName":"Team A"}`))
+		case "Bearer key-b":
+			w.Write([]byte(`{"success":true,"teamName":"Team B"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success":false,"message":"Invalid API key"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	keys := map[string]string{"a": "key-a", "b": "key-b", "bad": "nope"}
+	pool := NewClientPool(CredentialProviderFunc(func(_ context.Context, tenant string) (string, error) {
+		k, ok := keys[tenant]
+		if !ok {
+			return "", errors.New("unknown tenant")
+		}
+		return k, nil
+	}), WithPoolClientOptions(WithBaseURL(server.URL)))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	clients := make([]*Client, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := pool.Client(ctx, "a")
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = c
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range clients[1:] {
+		if c != clients[0] {
+			t.Fatal("expected the same client for the same tenant")
+		}
+	}
+	if got := atomic.LoadInt32(&verifications); got != 1 {
+		t.Errorf("verifications: got %d, want 1", got)
+	}
+	if name, ok := pool.TeamName("a"); !ok || name != "Team A" {
+		t.Errorf("TeamName(a) = %q, %v", name, ok)
+	}
+
+	b, err := pool.Client(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b == clients[0] || b.client != clients[0].client {
+		t.Error("tenants should get distinct clients sharing one http.Client")
+	}
+
+	_, err = pool.Client(ctx, "bad")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 verifying bad key, got %v", err)
+	}
+	if _, ok := pool.TeamName("bad"); ok {
+		t.Error("failed verification must not be cached")
+	}
+	if _, err := pool.Client(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "unknown tenant") {
+		t.Errorf("expected provider error, got %v", err)
+	}
+
+	pool.Evict("a")
+	if _, err := pool.Client(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&verifications); got != 4 {
+		t.Errorf("verifications after evict: got %d, want 4", got)
+	}
+}
+
+func TestClientPool_TenantRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"success":true,"teamName":"T"}`))
+	}))
+	t.Cleanup(server.Close)
+	pool := NewClientPool(CredentialProviderFunc(func(context.Context, string) (string, error) { return "k", nil }),
+		WithPoolClientOptions(WithBaseURL(server.URL)),
+		WithPoolRateLimit(1000, 10),
+		WithTenantRateLimit("slow", 20, 1),
+	)
+	ctx := context.Background()
+	slow, err := pool.Client(ctx, "slow") // verification spends the single burst token
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := slow.GetAPIKey(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("expected rate-limited calls to take >= ~100ms, took %s", elapsed)
+	}
+
+	ctx2, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	if _, err := slow.GetAPIKey(ctx2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected rate limit wait to honour ctx, got %v", err)
+	}
+}
+
+func TestClientPool_ClientOptionRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"success":true,"teamName":"T"}`))
+	}))
+	t.Cleanup(server.Close)
+	pool := NewClientPool(CredentialProviderFunc(func(context.Context, string) (string, error) { return "k", nil }),
+		WithPoolClientOptions(WithBaseURL(server.URL), WithRateLimit(5, 1)),
+		WithTenantRateLimit("fast", 0, 0),
+	)
+	ctx := context.Background()
+	client, err := pool.Client(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.limiter == nil {
+		t.Error("WithRateLimit from WithPoolClientOptions was dropped without a pool rate limit")
+	}
+	fast, err := pool.Client(ctx, "fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fast.limiter != nil {
+		t.Error("WithTenantRateLimit(0) should remove the client option's limit")
+	}
+}
+
+func TestClientPool_VerificationOutlivesFirstCaller(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.Write([]byte(`{"success":true,"teamName":"T"}`))
+	}))
+	t.Cleanup(server.Close)
+	var lookups int32
+	pool := NewClientPool(CredentialProviderFunc(func(context.Context, string) (string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return "k", nil
+	}), WithPoolClientOptions(WithBaseURL(server.URL)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Client(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("first caller: %v", err)
+	}
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := pool.Client(context.Background(), "a")
+		waiter <- err
+	}()
+	close(release)
+	if err := <-waiter; err != nil {
+		t.Fatalf("waiter failed because the first caller timed out: %v", err)
+	}
+	if name, ok := pool.TeamName("a"); !ok || name != "T" {
+		t.Errorf("TeamName = %q, %v", name, ok)
+	}
+
+	// The key resolved for verification is cached by the client, so its first request does not look it up again.
+	client, _ := pool.Client(context.Background(), "a")
+	if _, err := client.GetAPIKey(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("provider lookups: got %d, want 1", n)
+	}
+}
@@ -334,7 +334,7 @@ func TestClient_SendTransactional_SpecCompliant(t *testing.T) {
 func TestClient_ListTransactionals_SpecCompliant(t *testing.T) {
 	resp := ListTransactionalsResponse{
 		Pagination: ListTransactionalsPagination{TotalResults: 1, ReturnedResults: 1, PerPage: 20, TotalPages: 1},
-		Data:       []TransactionalEmail{{ID: "tx1", Name: "Welcome", LastUpdated: "2025-01-01", DataVariables: []string{"name"}}},
+		Data:       []TransactionalEmail{{ID: "tx1", Name: "Welcome", LastUpdated: timestampOf("2025-01-01"), DataVariables: []string{"name"}}},
 	}
 	body, _ := json.Marshal(resp)
 	var captured *http.Request
@@ -428,8 +428,8 @@ func TestClient_ListCampaigns_SpecCompliant(t *testing.T) {
 			Name:           "Launch",
 			Subject:        "Hello",
 			Status:         "Draft",
-			CreatedAt:      "2026-04-24T00:00:00Z",
-			UpdatedAt:      "2026-04-24T00:00:00Z",
+			CreatedAt:      timestampOf("2026-04-24T00:00:00Z"),
+			UpdatedAt:      timestampOf("2026-04-24T00:00:00Z"),
 		}},
 	}
 	body, _ := json.Marshal(resp)
@@ -465,8 +465,8 @@ func TestClient_CreateCampaign_SpecCompliant(t *testing.T) {
 		CampaignID:                    "camp_1",
 		Name:                          "Launch",
 		Status:                        "Draft",
-		CreatedAt:                     "2026-04-24T00:00:00Z",
-		UpdatedAt:                     "2026-04-24T00:00:00Z",
+		CreatedAt:                     timestampOf("2026-04-24T00:00:00Z"),
+		UpdatedAt:                     timestampOf("2026-04-24T00:00:00Z"),
 		EmailMessageID:                "msg_1",
 		EmailMessageContentRevisionID: stringPtr("rev_1"),
 	}
@@ -521,8 +521,8 @@ func TestClient_GetCampaign_SpecCompliant(t *testing.T) {
 		CampaignID:     "camp_1",
 		Name:           "Launch",
 		Status:         "Draft",
-		CreatedAt:      "2026-04-24T00:00:00Z",
-		UpdatedAt:      "2026-04-24T00:00:00Z",
+		CreatedAt:      timestampOf("2026-04-24T00:00:00Z"),
+		UpdatedAt:      timestampOf("2026-04-24T00:00:00Z"),
 		EmailMessageID: stringPtr("msg_1"),
 	}
 	body, _ := json.Marshal(resp)
@@ -563,8 +563,8 @@ func TestClient_UpdateCampaign_SpecCompliant(t *testing.T) {
 		CampaignID:     "camp_1",
 		Name:           "Renamed",
 		Status:         "Draft",
-		CreatedAt:      "2026-04-24T00:00:00Z",
-		UpdatedAt:      "2026-04-25T00:00:00Z",
+		CreatedAt:      timestampOf("2026-04-24T00:00:00Z"),
+		UpdatedAt:      timestampOf("2026-04-25T00:00:00Z"),
 		EmailMessageID: stringPtr("msg_1"),
 	}
 	body, _ := json.Marshal(resp)
@@ -628,7 +628,7 @@ func TestClient_GetEmailMessage_SpecCompliant(t *testing.T) {
 		ReplyToEmail:      "reply@example.com",
 		LMX:               "<Text>Hello</Text>",
 		ContentRevisionID: stringPtr("rev_1"),
-		UpdatedAt:         "2026-04-25T00:00:00Z",
+		UpdatedAt:         timestampOf("2026-04-25T00:00:00Z"),
 	}
 	body, _ := json.Marshal(resp)
 	var captured *http.Request
@@ -674,7 +674,7 @@ func TestClient_UpdateEmailMessage_SpecCompliant(t *testing.T) {
 		ReplyToEmail:      "reply@example.com",
 		LMX:               "<Text>Updated</Text>",
 		ContentRevisionID: stringPtr("rev_2"),
-		UpdatedAt:         "2026-04-25T00:00:00Z",
+		UpdatedAt:         timestampOf("2026-04-25T00:00:00Z"),
 	}
 	body, _ := json.Marshal(resp)
 	var captured *http.Request
@@ -739,8 +739,8 @@ func TestClient_ListThemes_SpecCompliant(t *testing.T) {
 			Name:      "Default",
 			Styles:    ThemeStyles{BackgroundColor: "white"},
 			IsDefault: true,
-			CreatedAt: "2026-04-24T00:00:00Z",
-			UpdatedAt: "2026-04-24T00:00:00Z",
+			CreatedAt: timestampOf("2026-04-24T00:00:00Z"),
+			UpdatedAt: timestampOf("2026-04-24T00:00:00Z"),
 		}},
 	}
 	body, _ := json.Marshal(resp)
@@ -777,8 +777,8 @@ func TestClient_GetTheme_SpecCompliant(t *testing.T) {
 		Name:      "Default",
 		Styles:    ThemeStyles{BackgroundColor: "white"},
 		IsDefault: true,
-		CreatedAt: "2026-04-24T00:00:00Z",
-		UpdatedAt: "2026-04-24T00:00:00Z",
+		CreatedAt: timestampOf("2026-04-24T00:00:00Z"),
+		UpdatedAt: timestampOf("2026-04-24T00:00:00Z"),
 	}
 	body, _ := json.Marshal(resp)
 	var captured *http.Request
@@ -0,0 +1,657 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+	"github.com/Whats-A-MattR/loops-go-sdk/content"
+	"github.com/Whats-A-MattR/loops-go-sdk/lmx"
+)
+
+// command is a leaf subcommand such as "contacts find". run parses its own flags from args with fs and returns
+// the value to print (nil prints nothing).
+type command struct {
+	path      string
+	args      string
+	summary   string
+	isDefault bool // run for the bare group name (e.g. "themes" runs "themes list")
+	run       func(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error)
+}
+
+var commands = []*command{
+	{path: "contacts find", args: "-email E | -user-id ID", summary: "Find a contact", run: contactsFind},
+	{path: "contacts create", args: "-data JSON", summary: "Create a contact (ContactRequest JSON)", run: contactsCreate},
+	{path: "contacts update", args: "-data JSON", summary: "Update a contact (ContactUpdateRequest JSON)", run: contactsUpdate},
+	{path: "contacts delete", args: "-email E | -user-id ID", summary: "Delete a contact", run: contactsDelete},
+	{path: "suppression get", args: "-email E | -user-id ID", summary: "Get suppression status and removal quota", run: suppressionGet},
+	{path: "suppression remove", args: "-email E | -user-id ID", summary: "Remove a contact from the suppression list", run: suppressionRemove},
+	{path: "properties list", args: "[-list all|custom]", summary: "List contact properties", run: propertiesList},
+	{path: "properties create", args: "-name N -type T", summary: "Create a contact property", run: propertiesCreate},
+	{path: "lists", summary: "List mailing lists", run: listsList},
+	{path: "events send", args: "-data JSON [-idempotency-key K]", summary: "Send an event (EventRequest JSON)", run: eventsSend},
+	{path: "transactional send", args: "-data JSON [-idempotency-key K]", summary: "Send a transactional email", run: transactionalSend},
+	{path: "transactional list", args: "[-per-page N] [-cursor C]", summary: "List published transactional emails", run: transactionalList},
+	{path: "campaigns list", args: "[-per-page N] [-cursor C]", summary: "List campaigns", run: campaignsList},
+	{path: "campaigns get", args: "<campaign-id>", summary: "Get a campaign", run: campaignsGet},
+	{path: "campaigns create", args: "-name N", summary: "Create a draft campaign", run: campaignsCreate},
+	{path: "campaigns deploy", args: "<dir> [-apply] [-state FILE]", summary: "Diff (and with -apply, deploy) campaign drafts from files", run: campaignsDeploy},
+	{path: "campaigns watch", args: "[-interval D] [-checkpoint FILE] [-once]", summary: "Print campaign changes (created, renamed, status, content) as JSON lines", run: campaignsWatch},
+	{path: "themes list", args: "[-per-page N] [-cursor C]", summary: "List themes", isDefault: true, run: themesList},
+	{path: "themes get", args: "<theme-id>", summary: "Get a theme", run: themesGet},
+	{path: "themes export", args: "<theme-id> [-format css|tokens] [-prefix P]", summary: "Print a theme as CSS custom properties or design tokens", run: themesExport},
+	{path: "components list", args: "[-per-page N] [-cursor C]", summary: "List components", isDefault: true, run: componentsList},
+	{path: "components get", args: "<component-id>", summary: "Get a component", run: componentsGet},
+	{path: "components sync", args: "<dir> [-pull] [-usage=false]", summary: "Report drift between components and <dir>/<name>.lmx (with -pull, update the files)", run: componentsSync},
+	{path: "email-messages get", args: "<email-message-id>", summary: "Get an email message", run: emailMessagesGet},
+	{path: "email-messages diff", args: "<old> <new> [-json]", summary: "Diff two email messages (IDs, or @file with saved get output)", run: emailMessagesDiff},
+	{path: "email-messages preview", args: "<email-message-id> [-theme ID] [-vars JSON] [-out FILE]", summary: "Render an email message as HTML", run: emailMessagesPreview},
+	{path: "email-messages update", args: "<email-message-id> [-data JSON] [-markdown FILE [-theme ID]]", summary: "Update an email message", run: emailMessagesUpdate},
+	{path: "usage", args: "[-component ID | -theme ID] [-json]", summary: "Report which campaigns use each component and theme", run: usageReport},
+	{path: "export", args: "<dir> [-incremental]", summary: "Back up all content to a directory of JSON and LMX files", run: exportContent},
+}
+
+// parseArgs parses flags that may appear before or after positional arguments and returns the positionals.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// oneArg parses flags and requires exactly one positional argument.
+func oneArg(fs *flag.FlagSet, args []string, name string) (string, error) {
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if len(pos) != 1 || pos[0] == "" {
+		return "", usageError(name + " is required")
+	}
+	return pos[0], nil
+}
+
+// noArgs parses flags and rejects positional arguments.
+func noArgs(fs *flag.FlagSet, args []string) error {
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return usageError(fmt.Sprintf("unexpected argument %q", pos[0]))
+	}
+	return nil
+}
+
+// identityFlags registers -email and -user-id.
+func identityFlags(fs *flag.FlagSet) (email, userID *string) {
+	return fs.String("email", "", "contact email"), fs.String("user-id", "", "contact userId")
+}
+
+func pageFlags(fs *flag.FlagSet) (perPage *int, cursor *string) {
+	return fs.Int("per-page", 0, "results per page (10-50)"), fs.String("cursor", "", "pagination cursor")
+}
+
+// dataFlag registers -data: inline JSON, @path to read a file, or - for stdin.
+func dataFlag(fs *flag.FlagSet) *string {
+	return fs.String("data", "", "request JSON: inline, @file, or - for stdin (default: stdin when piped)")
+}
+
+// readData returns the request JSON selected by -data.
+func (c *cli) readData(data string) ([]byte, error) {
+	var b []byte
+	var err error
+	switch {
+	case data == "-" || (data == "" && c.stdinPiped):
+		b, err = io.ReadAll(c.stdin)
+	case strings.HasPrefix(data, "@"):
+		b, err = os.ReadFile(data[1:])
+	default:
+		b = []byte(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, usageError("request JSON is required (-data)")
+	}
+	return b, nil
+}
+
+// decodeData reads -data into v, rejecting unknown fields.
+func (c *cli) decodeData(data string, v interface{}) error {
+	b, err := c.readData(data)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request JSON: %w", err)
+	}
+	return nil
+}
+
+// decodeDataWithExtra reads -data into v and returns the keys v does not declare, for request types whose Extra
+// field carries custom contact properties.
+func (c *cli) decodeDataWithExtra(data string, v interface{}) (map[string]interface{}, error) {
+	b, err := c.readData(data)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, fmt.Errorf("invalid request JSON: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, fmt.Errorf("invalid request JSON: %w", err)
+	}
+	for _, name := range jsonFields(v) {
+		delete(all, name)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+func contactsFind(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	email, userID := identityFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.FindContact(ctx, *email, *userID)
+}
+
+func contactsCreate(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	data := dataFlag(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	var req loops.ContactRequest
+	extra, err := c.decodeDataWithExtra(*data, &req)
+	if err != nil {
+		return nil, err
+	}
+	req.Extra = extra
+	return c.client.CreateContact(ctx, &req)
+}
+
+func contactsUpdate(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	data := dataFlag(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	var req loops.ContactUpdateRequest
+	extra, err := c.decodeDataWithExtra(*data, &req)
+	if err != nil {
+		return nil, err
+	}
+	req.Extra = extra
+	return c.client.UpdateContact(ctx, &req)
+}
+
+func contactsDelete(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	email, userID := identityFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.DeleteContact(ctx, &loops.ContactDeleteRequest{Email: *email, UserID: *userID})
+}
+
+func suppressionGet(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	email, userID := identityFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.GetContactSuppression(ctx, *email, *userID)
+}
+
+func suppressionRemove(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	email, userID := identityFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.DeleteContactSuppression(ctx, *email, *userID)
+}
+
+func propertiesList(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	list := fs.String("list", "", `"all" or "custom"`)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.ListContactProperties(ctx, *list)
+}
+
+func propertiesCreate(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	name := fs.String("name", "", "property name (camelCase)")
+	typ := fs.String("type", "", "property type: string, number, boolean or date")
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.CreateContactProperty(ctx, &loops.ContactPropertyCreateRequest{Name: *name, Type: loops.PropertyType(*typ)})
+}
+
+func listsList(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.GetLists(ctx)
+}
+
+func eventsSend(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	data := dataFlag(fs)
+	key := fs.String("idempotency-key", "", "Idempotency-Key header (max 100 chars)")
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	var req loops.EventRequest
+	extra, err := c.decodeDataWithExtra(*data, &req)
+	if err != nil {
+		return nil, err
+	}
+	req.Extra = extra
+	return c.client.SendEvent(ctx, &req, *key)
+}
+
+func transactionalSend(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	data := dataFlag(fs)
+	key := fs.String("idempotency-key", "", "Idempotency-Key header (max 100 chars)")
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	var req loops.TransactionalRequest
+	if err := c.decodeData(*data, &req); err != nil {
+		return nil, err
+	}
+	return c.client.SendTransactional(ctx, &req, *key)
+}
+
+func transactionalList(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	perPage, cursor := pageFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.ListTransactionals(ctx, *perPage, *cursor)
+}
+
+func campaignsList(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	perPage, cursor := pageFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.ListCampaigns(ctx, *perPage, *cursor)
+}
+
+func campaignsGet(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	id, err := oneArg(fs, args, "campaign-id")
+	if err != nil {
+		return nil, err
+	}
+	return c.client.GetCampaign(ctx, id)
+}
+
+func campaignsCreate(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	name := fs.String("name", "", "campaign name")
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.CreateCampaign(ctx, &loops.CreateCampaignRequest{Name: *name})
+}
+
+func campaignsDeploy(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	apply := fs.Bool("apply", false, "create and update the drafts (default: only show the diff)")
+	statePath := fs.String("state", "", "state file (default <dir>/"+content.StateFile+")")
+	dir, err := oneArg(fs, args, "dir")
+	if err != nil {
+		return nil, err
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(dir, content.StateFile)
+	}
+	specs, err := content.LoadCampaigns(dir)
+	if err != nil {
+		return nil, err
+	}
+	state, err := content.LoadState(*statePath)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := content.PlanDeploy(ctx, c.client, specs, state)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := plan.WriteTo(c.stdout); err != nil || !*apply {
+		return nil, err
+	}
+	err = content.ApplyDeploy(ctx, c.client, plan, state)
+	if serr := state.Save(*statePath); serr != nil && err == nil {
+		err = serr
+	}
+	return nil, err
+}
+
+func campaignsWatch(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	interval := fs.Duration("interval", loops.DefaultCampaignWatchInterval, "time between polls")
+	checkpoint := fs.String("checkpoint", "", "file that records the last seen campaigns across restarts")
+	once := fs.Bool("once", false, "poll once and exit (report every campaign as created if there is no checkpoint)")
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	w, err := c.client.NewCampaignWatcher(loops.CampaignWatchOptions{
+		Interval:       *interval,
+		CheckpointPath: *checkpoint,
+		EmitExisting:   *once,
+		OnError:        func(err error) { fmt.Fprintf(c.stderr, "%s: %v\n", time.Now().Format(time.RFC3339), err) },
+	})
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(c.stdout)
+	if *once {
+		events, err := w.Poll(ctx)
+		for _, ev := range events {
+			if err := enc.Encode(ev); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	err = w.Run(ctx, func(ev loops.CampaignEvent) error { return enc.Encode(ev) })
+	if errors.Is(err, context.Canceled) {
+		return nil, nil // interrupted
+	}
+	return nil, err
+}
+
+func themesList(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	perPage, cursor := pageFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.ListThemes(ctx, *perPage, *cursor)
+}
+
+func themesGet(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	id, err := oneArg(fs, args, "theme-id")
+	if err != nil {
+		return nil, err
+	}
+	return c.client.GetTheme(ctx, id)
+}
+
+func themesExport(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	format := fs.String("format", "css", "css (custom properties) or tokens (W3C design tokens JSON)")
+	prefix := fs.String("prefix", "", "custom property prefix for css, or token group path for tokens")
+	id, err := oneArg(fs, args, "theme-id")
+	if err != nil {
+		return nil, err
+	}
+	if *format != "css" && *format != "tokens" {
+		return nil, usageError(fmt.Sprintf("unknown format %q (want css or tokens)", *format))
+	}
+	theme, err := c.client.GetTheme(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := theme.Styles.CSS("", *prefix)
+	if *format == "tokens" {
+		b, err := theme.Styles.MarshalDesignTokens(*prefix)
+		if err != nil {
+			return nil, err
+		}
+		out = string(b) + "\n"
+	}
+	_, err = io.WriteString(c.stdout, out)
+	return nil, err
+}
+
+func componentsList(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	perPage, cursor := pageFlags(fs)
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	return c.client.ListComponents(ctx, *perPage, *cursor)
+}
+
+func componentsGet(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	id, err := oneArg(fs, args, "component-id")
+	if err != nil {
+		return nil, err
+	}
+	return c.client.GetComponent(ctx, id)
+}
+
+func componentsSync(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	pull := fs.Bool("pull", false, "write new and changed components to disk")
+	usage := fs.Bool("usage", true, "list the campaigns that use each component (fetches every email message)")
+	dir, err := oneArg(fs, args, "dir")
+	if err != nil {
+		return nil, err
+	}
+	return content.SyncComponents(ctx, c.client, dir, content.ComponentSyncOptions{Pull: *pull, Usage: *usage})
+}
+
+func emailMessagesGet(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	id, err := oneArg(fs, args, "email-message-id")
+	if err != nil {
+		return nil, err
+	}
+	return c.client.GetEmailMessage(ctx, id)
+}
+
+func emailMessagesUpdate(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	data := dataFlag(fs)
+	markdown := fs.String("markdown", "", "set the LMX body from this Markdown file (- for stdin)")
+	themeID := fs.String("theme", "", "with -markdown, embed this theme's styles")
+	id, err := oneArg(fs, args, "email-message-id")
+	if err != nil {
+		return nil, err
+	}
+	var req loops.UpdateEmailMessageRequest
+	if *markdown == "" || *data != "" {
+		if err := c.decodeData(*data, &req); err != nil {
+			return nil, err
+		}
+	}
+	if *markdown == "" {
+		return c.client.UpdateEmailMessage(ctx, id, &req)
+	}
+
+	var src []byte
+	if *markdown == "-" {
+		src, err = io.ReadAll(c.stdin)
+	} else {
+		src, err = os.ReadFile(*markdown)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var opts lmx.MarkdownOptions
+	if *themeID != "" {
+		theme, err := c.client.GetTheme(ctx, *themeID)
+		if err != nil {
+			return nil, err
+		}
+		opts.Styles = &theme.Styles
+	}
+	req.LMX = lmx.FromMarkdown(string(src), opts).String()
+	if req.ExpectedRevisionID == "" {
+		// Publishing from a file replaces the body, so default to the current revision.
+		current, err := c.client.GetEmailMessage(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.ContentRevisionID != nil {
+			req.ExpectedRevisionID = *current.ContentRevisionID
+		}
+	}
+	return c.client.UpdateEmailMessage(ctx, id, &req)
+}
+
+func emailMessagesDiff(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	asJSON := fs.Bool("json", false, "print the diff as JSON")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(pos) != 2 {
+		return nil, usageError("expected <old> and <new>")
+	}
+	var msgs [2]*loops.EmailMessageResponse
+	for i, ref := range pos {
+		if !strings.HasPrefix(ref, "@") {
+			if msgs[i], err = c.client.GetEmailMessage(ctx, ref); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		b, err := os.ReadFile(ref[1:])
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &msgs[i]); err != nil {
+			return nil, fmt.Errorf("%s: %w", ref[1:], err)
+		}
+	}
+	d, err := lmx.DiffMessages(msgs[0], msgs[1])
+	if err != nil {
+		return nil, err
+	}
+	out := []byte(d.Unified(pos[0], pos[1]))
+	if *asJSON {
+		if out, err = d.JSON(); err != nil {
+			return nil, err
+		}
+	}
+	_, err = c.stdout.Write(out)
+	return nil, err
+}
+
+func emailMessagesPreview(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	themeID := fs.String("theme", "", "theme whose styles to apply")
+	vars := fs.String("vars", "", "sample data variables as a JSON object: inline or @file")
+	out := fs.String("out", "", "write the HTML to this file instead of stdout")
+	id, err := oneArg(fs, args, "email-message-id")
+	if err != nil {
+		return nil, err
+	}
+	opts := lmx.HTMLOptions{Components: make(map[string]string)}
+	if *vars != "" {
+		b := []byte(*vars)
+		if strings.HasPrefix(*vars, "@") {
+			if b, err = os.ReadFile((*vars)[1:]); err != nil {
+				return nil, err
+			}
+		}
+		if err := json.Unmarshal(b, &opts.Data); err != nil {
+			return nil, fmt.Errorf("invalid -vars JSON: %w", err)
+		}
+	}
+	msg, err := c.client.GetEmailMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := lmx.Parse(msg.LMX)
+	if err != nil {
+		return nil, err
+	}
+	if *themeID != "" {
+		theme, err := c.client.GetTheme(ctx, *themeID)
+		if err != nil {
+			return nil, err
+		}
+		opts.Styles = theme.Styles
+	}
+	for _, ref := range componentRefs(doc.Children) {
+		if _, ok := opts.Components[ref]; ok {
+			continue
+		}
+		comp, err := c.client.GetComponent(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", ref, err)
+		}
+		opts.Components[ref] = comp.LMX
+	}
+	opts.Subject, opts.PreviewText = msg.Subject, msg.PreviewText
+	page, err := lmx.RenderHTML(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	if *out != "" {
+		return nil, os.WriteFile(*out, []byte(page), 0o644)
+	}
+	_, err = io.WriteString(c.stdout, page)
+	return nil, err
+}
+
+func exportContent(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	incremental := fs.Bool("incremental", false, "only refetch campaigns whose updatedAt changed since the last export")
+	dir, err := oneArg(fs, args, "dir")
+	if err != nil {
+		return nil, err
+	}
+	return content.Export(ctx, c.client, dir, content.ExportOptions{Incremental: *incremental})
+}
+
+func usageReport(ctx context.Context, c *cli, fs *flag.FlagSet, args []string) (interface{}, error) {
+	componentID := fs.String("component", "", "only report the campaigns that use this component")
+	themeID := fs.String("theme", "", "only report the campaigns that use this theme")
+	asJSON := fs.Bool("json", false, "print the full index as JSON")
+	if err := noArgs(fs, args); err != nil {
+		return nil, err
+	}
+	if *componentID != "" && *themeID != "" {
+		return nil, usageError("-component and -theme are mutually exclusive")
+	}
+	u, err := content.AnalyzeUsage(ctx, c.client)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case *componentID != "":
+		if e := u.Component(*componentID); e != nil {
+			return e.Campaigns, nil
+		}
+		return nil, fmt.Errorf("component %s not found", *componentID)
+	case *themeID != "":
+		if e := u.Theme(*themeID); e != nil {
+			return e.Campaigns, nil
+		}
+		return nil, fmt.Errorf("theme %s not found", *themeID)
+	case *asJSON:
+		return u, nil
+	}
+	return nil, u.WriteReport(c.stdout)
+}
+
+// componentRefs returns the IDs of <Component /> references in nodes, in document order.
+func componentRefs(nodes []lmx.Node) []string {
+	var ids []string
+	for _, n := range nodes {
+		if e, ok := n.(*lmx.Element); ok {
+			if id, ok := e.Get("id"); ok && e.Name == lmx.TagComponent {
+				ids = append(ids, id)
+			}
+			ids = append(ids, componentRefs(e.Children)...)
+		}
+	}
+	return ids
+}
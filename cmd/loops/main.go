@@ -0,0 +1,179 @@
+// Command loops is a command-line interface to the Loops API built on the loops Go SDK.
+//
+// Usage:
+//
+//	loops [-o json|table] [-base-url URL] <command> [subcommand] [flags]
+//
+// The API key is read from LOOPS_API_KEY (and the base URL, optionally, from LOOPS_BASE_URL). Request bodies are
+// JSON given with -data, read from a file with -data @path, or read from stdin with -data - (or when stdin is piped).
+// Run "loops help" for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr))
+}
+
+// cli carries everything a command needs; it keeps commands testable without touching the process environment.
+type cli struct {
+	client *loops.Client
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// stdinPiped reports whether stdin should be read when -data is omitted.
+	stdinPiped bool
+}
+
+// run executes one invocation and returns the process exit code.
+func run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("loops", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	output := global.String("o", "json", "output format: json or table")
+	baseURL := global.String("base-url", "", "API base URL (default $LOOPS_BASE_URL or "+loops.DefaultBaseURL+")")
+	global.Usage = func() { printUsage(stderr) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	args = global.Args()
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+	if *output != "json" && *output != "table" {
+		fmt.Fprintf(stderr, "loops: unknown output format %q (want json or table)\n", *output)
+		return 2
+	}
+
+	cmd, rest, err := lookup(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "loops: %v\n", err)
+		printUsage(stderr)
+		return 2
+	}
+	apiKey := getenv("LOOPS_API_KEY")
+	if apiKey == "" {
+		fmt.Fprintln(stderr, "loops: LOOPS_API_KEY is not set")
+		return 2
+	}
+	opts := []loops.ClientOption{}
+	if *baseURL == "" {
+		*baseURL = getenv("LOOPS_BASE_URL")
+	}
+	if *baseURL != "" {
+		opts = append(opts, loops.WithBaseURL(*baseURL))
+	}
+	c := &cli{
+		client:     loops.NewClient(apiKey, opts...),
+		stdin:      stdin,
+		stdout:     stdout,
+		stderr:     stderr,
+		stdinPiped: isPiped(stdin),
+	}
+
+	fs := flag.NewFlagSet("loops "+cmd.path, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: loops %s %s\n", cmd.path, cmd.args)
+		fs.PrintDefaults()
+	}
+	result, err := cmd.run(ctx, c, fs, rest)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(stderr, "loops %s: %v\n", cmd.path, err)
+			fs.Usage()
+			return 2
+		}
+		fmt.Fprintf(stderr, "loops %s: %v\n", cmd.path, err)
+		return 1
+	}
+	if result == nil {
+		return 0
+	}
+	if *output == "table" {
+		err = writeTable(stdout, result)
+	} else {
+		err = writeJSON(stdout, result)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "loops: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// usageError marks errors caused by bad invocation (exit code 2, usage printed).
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// lookup finds the command for args ("contacts find ...", "lists", ...) and returns the remaining arguments.
+func lookup(args []string) (*command, []string, error) {
+	for _, cmd := range commands {
+		parts := strings.Fields(cmd.path)
+		if len(args) < len(parts) {
+			continue
+		}
+		match := true
+		for i, p := range parts {
+			if args[i] != p {
+				match = false
+				break
+			}
+		}
+		if match {
+			return cmd, args[len(parts):], nil
+		}
+	}
+	// Bare group names ("themes", "components") default to their list subcommand.
+	for _, cmd := range commands {
+		if cmd.path == args[0]+" list" && cmd.isDefault {
+			return cmd, args[1:], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unknown command %q", strings.Join(args, " "))
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: loops [-o json|table] [-base-url URL] <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The API key is read from LOOPS_API_KEY.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-28s %s\n", cmd.path+" "+cmd.args, cmd.summary)
+	}
+}
+
+// isPiped reports whether r is a pipe or file rather than an interactive terminal.
+func isPiped(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return r != nil
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice == 0
+}
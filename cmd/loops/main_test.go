@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRun runs the CLI against server with the given stdin and returns exit code, stdout and stderr.
+func testRun(t *testing.T, server *httptest.Server, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	env := map[string]string{"LOOPS_API_KEY": "key", "LOOPS_BASE_URL": server.URL}
+	var stdout, stderr bytes.Buffer
+	var in io.Reader
+	if stdin != "" {
+		in = strings.NewReader(stdin)
+	}
+	code := run(context.Background(), args, func(k string) string { return env[k] }, in, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_ContactsFind_JSON(t *testing.T) {
+	var captured *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r
+		w.Write([]byte(`[{"id":"c1","email":"u@example.com","subscribed":true}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	code, out, errOut := testRun(t, server, "", "contacts", "find", "-email", "u@example.com")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if captured.URL.Path != "/contacts/find" || captured.URL.Query().Get("email") != "u@example.com" {
+		t.Errorf("request: %s", captured.URL)
+	}
+	if auth := captured.Header.Get("Authorization"); auth != "Bearer key" {
+		t.Errorf("Authorization: %q", auth)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &got); err != nil || len(got) != 1 || got[0]["id"] != "c1" {
+		t.Errorf("output: %s (%v)", out, err)
+	}
+}
+
+func TestRun_ContactsCreate_StdinWithCustomProperties(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"success":true,"id":"c1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	code, _, errOut := testRun(t, server, `{"email":"u@example.com","firstName":"Jane","plan":"pro"}`, "contacts", "create")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if body["email"] != "u@example.com" || body["firstName"] != "Jane" || body["plan"] != "pro" {
+		t.Errorf("request body: %v", body)
+	}
+}
+
+func TestRun_CampaignsList_Table(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("perPage") != "10" {
+			t.Errorf("query: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"success":true,"pagination":{},"data":[{"campaignId":"cmp_1","name":"Launch","status":"Draft","updatedAt":"2026-04-24T09:30:00Z"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	code, out, errOut := testRun(t, server, "", "-o", "table", "campaigns", "list", "-per-page", "10")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "CAMPAIGNID") || !strings.Contains(lines[1], "cmp_1") || !strings.Contains(lines[1], "Launch") ||
+		!strings.Contains(lines[1], " 2026-04-24T09:30:00Z") {
+		t.Errorf("table output:\n%s", out)
+	}
+}
+
+func TestRun_PositionalThenFlags(t *testing.T) {
+	var captured *http.Request
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"success":true,"emailMessageId":"em_1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	code, _, errOut := testRun(t, server, "", "email-messages", "update", "em_1", "-data", `{"subject":"Hi"}`)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	if captured.URL.Path != "/email-messages/em_1" || body["subject"] != "Hi" {
+		t.Errorf("path=%s body=%v", captured.URL.Path, body)
+	}
+}
+
+func TestRun_EmailMessagesUpdate_Markdown(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/themes/thm_1":
+			w.Write([]byte(`{"success":true,"themeId":"thm_1","styles":{"bodyColor":"#eee"}}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"success":true,"emailMessageId":"em_1","contentRevisionId":"rev_7"}`))
+		default:
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"success":true,"emailMessageId":"em_1"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	md := filepath.Join(t.TempDir(), "launch.md")
+	os.WriteFile(md, []byte("# Launch\n\nIt's **here**."), 0o600)
+	code, _, errOut := testRun(t, server, "", "email-messages", "update", "em_1", "-markdown", md, "-theme", "thm_1", "-data", `{"subject":"Launch"}`)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	want := "<Style bodyColor=\"#eee\" />\n<Heading level=\"1\">Launch</Heading>\n<Text>It's <Bold>here</Bold>.</Text>"
+	if body["lmx"] != want || body["expectedRevisionId"] != "rev_7" || body["subject"] != "Launch" {
+		t.Errorf("request body: %v", body)
+	}
+}
+
+func TestRun_EmailMessagesPreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/email-messages/em_1":
+			w.Write([]byte(`{"success":true,"emailMessageId":"em_1","subject":"Hello","lmx":"<Text>Hi <Variable name=\"firstName\" /></Text><Component id=\"cmp_1\" />"}`))
+		case "/components/cmp_1":
+			w.Write([]byte(`{"success":true,"componentId":"cmp_1","name":"Footer","lmx":"<Text>Footer</Text>"}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	code, out, errOut := testRun(t, server, "", "email-messages", "preview", "em_1", "-vars", `{"firstName":"Ada"}`)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	for _, want := range []string{"<title>Hello</title>", ">Hi Ada</p>", ">Footer</p>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_CampaignsDeploy(t *testing.T) {
+	var posts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/campaigns":
+			w.Write([]byte(`{"success":true,"pagination":{},"data":[]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/campaigns":
+			posts = append(posts, r.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"success":true,"campaignId":"cmp_1","name":"Welcome","status":"Draft","emailMessageId":"em_1","emailMessageContentRevisionId":"rev_1"}`))
+		case r.Method == http.MethodPost:
+			posts = append(posts, r.URL.Path)
+			w.Write([]byte(`{"success":true,"emailMessageId":"em_1"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "welcome"), 0o755)
+	os.WriteFile(filepath.Join(dir, "welcome", "campaign.json"), []byte(`{"name":"Welcome","subject":"Hi"}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "welcome", "body.lmx"), []byte("<Text>Hi</Text>"), 0o644)
+
+	code, out, errOut := testRun(t, server, "", "campaigns", "deploy", dir)
+	if code != 0 || !strings.Contains(out, "+ create welcome\n") || len(posts) != 0 {
+		t.Fatalf("dry run: exit %d, posts %v, stdout %q, stderr %q", code, posts, out, errOut)
+	}
+	code, _, errOut = testRun(t, server, "", "campaigns", "deploy", "-apply", dir)
+	if code != 0 || len(posts) != 2 {
+		t.Fatalf("apply: exit %d, posts %v, stderr %q", code, posts, errOut)
+	}
+	state, _ := os.ReadFile(filepath.Join(dir, ".loops-state.json"))
+	if !strings.Contains(string(state), `"welcome": "cmp_1"`) {
+		t.Errorf("state file: %s", state)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"success":false,"message":"Campaign not found"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	if code, _, errOut := testRun(t, server, "", "campaigns", "get", "nope"); code != 1 || !strings.Contains(errOut, "Campaign not found") {
+		t.Errorf("API error: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, _ := testRun(t, server, "", "nonsense"); code != 2 {
+		t.Errorf("unknown command: exit %d, want 2", code)
+	}
+	if code, _, _ := testRun(t, server, "", "campaigns", "get"); code != 2 {
+		t.Errorf("missing argument: exit %d, want 2", code)
+	}
+	if code, _, _ := testRun(t, server, "", "email-messages", "update", "em_1", "-data", `{"bogus":1}`); code != 1 {
+		t.Errorf("unknown field: exit %d, want 1", code)
+	}
+
+	var stderr bytes.Buffer
+	code := run(context.Background(), []string{"lists"}, func(string) string { return "" }, nil, io.Discard, &stderr)
+	if code != 2 || !strings.Contains(stderr.String(), "LOOPS_API_KEY") {
+		t.Errorf("missing key: exit %d, stderr %q", code, stderr.String())
+	}
+}
+
+func TestRun_Export(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lists", "/contacts/properties":
+			w.Write([]byte(`[]`))
+		default:
+			w.Write([]byte(`{"success":true,"pagination":{},"data":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+	code, out, errOut := testRun(t, server, "", "export", dir)
+	if code != 0 || !strings.Contains(out, `"export.json"`) {
+		t.Fatalf("exit %d: stdout %q stderr %q", code, out, errOut)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lists.json")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRun_EmailMessagesDiff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"emailMessageId":"em_1","subject":"New","lmx":"<Text>Hi</Text>\n<Divider />"}`))
+	}))
+	t.Cleanup(server.Close)
+	before := filepath.Join(t.TempDir(), "before.json")
+	os.WriteFile(before, []byte(`{"emailMessageId":"em_1","subject":"Old","lmx":"<Text>Hi</Text>"}`), 0o644)
+
+	code, out, errOut := testRun(t, server, "", "email-messages", "diff", "@"+before, "em_1")
+	want := "--- @" + before + "\n+++ em_1\n@@ subject @@\n-Old\n+New\n@@ /Divider[1] @@\n+<Divider />\n"
+	if code != 0 || out != want {
+		t.Fatalf("exit %d: stdout %q stderr %q", code, out, errOut)
+	}
+	code, out, _ = testRun(t, server, "", "email-messages", "diff", "-json", "@"+before, "em_1")
+	if code != 0 || !strings.Contains(out, `"new": "<Divider />"`) {
+		t.Errorf("JSON output: %s", out)
+	}
+}
+
+func TestRun_ThemesExport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"themeId":"thm_1","name":"Brand","styles":{"bodyColor":"#fff","bodyXPadding":24}}`))
+	}))
+	t.Cleanup(server.Close)
+	code, out, errOut := testRun(t, server, "", "themes", "export", "thm_1")
+	if code != 0 || out != ":root {\n  --loops-body-color: #fff;\n  --loops-body-x-padding: 24px;\n}\n" {
+		t.Fatalf("css: exit %d: %q %q", code, out, errOut)
+	}
+	code, out, _ = testRun(t, server, "", "themes", "export", "thm_1", "-format", "tokens", "-prefix", "brand")
+	if code != 0 || !strings.Contains(out, `"bodyXPadding": {`) || !strings.HasPrefix(out, "{\n  \"brand\": {") {
+		t.Errorf("tokens: exit %d: %s", code, out)
+	}
+	if code, _, _ := testRun(t, server, "", "themes", "export", "thm_1", "-format", "scss"); code != 2 {
+		t.Errorf("bad format: exit %d", code)
+	}
+}
+
+func TestRun_ComponentsSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/components":
+			w.Write([]byte(`{"success":true,"pagination":{},"data":[{"componentId":"c1","name":"Footer","lmx":"<Text>Footer</Text>"}]}`))
+		default:
+			w.Write([]byte(`{"success":true,"pagination":{},"data":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+	code, out, errOut := testRun(t, server, "", "-o", "table", "components", "sync", dir, "-pull")
+	if code != 0 || !strings.Contains(out, "Footer.lmx") || !strings.Contains(out, "new") {
+		t.Fatalf("exit %d: stdout %q stderr %q", code, out, errOut)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "Footer.lmx")); string(b) != "<Text>Footer</Text>" {
+		t.Errorf("Footer.lmx: %q", b)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/components":
+			w.Write([]byte(`{"success":true,"pagination":{},"data":[{"componentId":"c1","name":"Footer","lmx":""}]}`))
+		case "/campaigns":
+			w.Write([]byte(`{"success":true,"pagination":{},"data":[{"campaignId":"cmp_1","emailMessageId":"em_1","name":"Launch","status":"Draft"}]}`))
+		case "/email-messages/em_1":
+			w.Write([]byte(`{"success":true,"emailMessageId":"em_1","lmx":"<Component id=\"c1\" />"}`))
+		default:
+			w.Write([]byte(`{"success":true,"pagination":{},"data":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	code, out, errOut := testRun(t, server, "", "usage")
+	if code != 0 || out != "component c1 (Footer): 1 campaign\n  cmp_1  Draft  Launch\n" {
+		t.Fatalf("exit %d: stdout %q stderr %q", code, out, errOut)
+	}
+	code, out, _ = testRun(t, server, "", "-o", "table", "usage", "-component", "c1")
+	if code != 0 || !strings.Contains(out, "cmp_1") {
+		t.Errorf("-component: exit %d: %s", code, out)
+	}
+	if code, _, _ := testRun(t, server, "", "usage", "-component", "c1", "-theme", "t1"); code != 2 {
+		t.Errorf("both filters: exit %d", code)
+	}
+}
+
+func TestRun_CampaignsWatchOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"pagination":{},"data":[{"campaignId":"cmp_1","name":"Launch","status":"Draft","updatedAt":"1"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	checkpoint := filepath.Join(t.TempDir(), "campaigns.json")
+	code, out, errOut := testRun(t, server, "", "campaigns", "watch", "-once", "-checkpoint", checkpoint)
+	if code != 0 || !strings.Contains(out, `"type":"created"`) || !strings.Contains(out, `"campaignId":"cmp_1"`) {
+		t.Fatalf("exit %d: stdout %q stderr %q", code, out, errOut)
+	}
+	if code, out, _ := testRun(t, server, "", "campaigns", "watch", "-once", "-checkpoint", checkpoint); code != 0 || out != "" {
+		t.Errorf("second run: exit %d, stdout %q", code, out)
+	}
+}
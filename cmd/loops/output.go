@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeTable prints v as a table. Slices (or responses with a Data slice, i.e. paginated lists) print one row
+// per element with a column per JSON field; other values print as FIELD/VALUE rows. Nested values are shown as
+// compact JSON.
+func writeTable(w io.Writer, v interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() == reflect.Struct {
+		if data := rv.FieldByName("Data"); data.IsValid() && data.Kind() == reflect.Slice {
+			rv = data
+		}
+	}
+	if rv.Kind() == reflect.Slice {
+		elem := rv.Type().Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			fmt.Fprintln(tw, "VALUE")
+			for i := 0; i < rv.Len(); i++ {
+				fmt.Fprintln(tw, cell(rv.Index(i)))
+			}
+			return tw.Flush()
+		}
+		fields := tableFields(elem)
+		headers := make([]string, len(fields))
+		for i, f := range fields {
+			headers[i] = strings.ToUpper(f.name)
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		for i := 0; i < rv.Len(); i++ {
+			row := reflect.Indirect(rv.Index(i))
+			cells := make([]string, len(fields))
+			for j, f := range fields {
+				cells[j] = cell(row.FieldByIndex(f.index))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+	if rv.Kind() == reflect.Struct {
+		fmt.Fprintln(tw, "FIELD\tVALUE")
+		for _, f := range tableFields(rv.Type()) {
+			fmt.Fprintf(tw, "%s\t%s\n", f.name, cell(rv.FieldByIndex(f.index)))
+		}
+		return tw.Flush()
+	}
+	fmt.Fprintln(tw, cell(rv))
+	return tw.Flush()
+}
+
+type tableField struct {
+	name  string
+	index []int
+}
+
+// tableFields returns the JSON-visible fields of struct type t in declaration order.
+func tableFields(t reflect.Type) []tableField {
+	var out []tableField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+		out = append(out, tableField{name: name, index: f.Index})
+	}
+	return out
+}
+
+// jsonFields returns the JSON field names declared by the struct v points to.
+func jsonFields(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var names []string
+	for _, f := range tableFields(t) {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+func cell(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok && v.Kind() == reflect.Struct {
+		return oneLine(s.String()) // e.g. loops.Timestamp as received
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return oneLine(v.String())
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return oneLine(string(b))
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// oneLine keeps multi-line values (e.g. LMX) from breaking table rows.
+func oneLine(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "\t", " ")
+}
@@ -3,15 +3,19 @@ package loops
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 )
 
-// CreateContactProperty creates a contact property (POST /contacts/properties). Name and type required per OpenAPI.
+// CreateContactProperty creates a contact property (POST /contacts/properties). Name and a valid PropertyType are required.
 func (c *Client) CreateContactProperty(ctx context.Context, req *ContactPropertyCreateRequest) (*ContactPropertySuccessResponse, error) {
 	if req == nil || req.Name == "" || req.Type == "" {
 		return nil, &APIError{StatusCode: 400, Message: "name and type are required"}
 	}
+	if !req.Type.IsValid() {
+		return nil, &APIError{StatusCode: 400, Message: fmt.Sprintf("invalid type %q (want string, number, boolean or date)", req.Type)}
+	}
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
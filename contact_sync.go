@@ -0,0 +1,337 @@
+package loops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DesiredContact is the state a source of truth wants a contact to have in Loops. Identify the contact by Email
+// (preferred) or UserID. Nil or absent fields are not managed: they are neither compared nor changed.
+type DesiredContact struct {
+	Email      string
+	UserID     string
+	FirstName  *string
+	LastName   *string
+	UserGroup  *string
+	Subscribed *bool
+	// Properties are custom contact properties.
+	Properties map[string]interface{}
+	// MailingLists maps list IDs to the desired subscription; lists not mentioned are left alone.
+	MailingLists map[string]bool
+	// Deleted marks a contact that should no longer exist (e.g. a deleted user); see SyncOptions.DeletePolicy.
+	Deleted bool
+}
+
+// ContactSource yields desired contacts. Next returns io.EOF when the source is exhausted.
+type ContactSource interface {
+	Next(ctx context.Context) (DesiredContact, error)
+}
+
+// ContactSourceFunc adapts a function to ContactSource.
+type ContactSourceFunc func(ctx context.Context) (DesiredContact, error)
+
+// Next calls f(ctx).
+func (f ContactSourceFunc) Next(ctx context.Context) (DesiredContact, error) { return f(ctx) }
+
+// SliceContactSource returns a ContactSource over contacts.
+func SliceContactSource(contacts []DesiredContact) ContactSource {
+	i := 0
+	return ContactSourceFunc(func(context.Context) (DesiredContact, error) {
+		if i >= len(contacts) {
+			return DesiredContact{}, io.EOF
+		}
+		i++
+		return contacts[i-1], nil
+	})
+}
+
+// DeletePolicy controls what SyncContacts does with contacts marked Deleted.
+type DeletePolicy int
+
+const (
+	// NeverDelete skips deleted contacts.
+	NeverDelete DeletePolicy = iota
+	// SoftUnsubscribe keeps the contact but sets subscribed to false.
+	SoftUnsubscribe
+	// HardDelete deletes the contact with DeleteContact.
+	HardDelete
+)
+
+// SyncAction is what SyncContacts did (or, in a dry run, would do) for one contact.
+type SyncAction string
+
+const (
+	SyncCreate      SyncAction = "create"
+	SyncUpdate      SyncAction = "update"
+	SyncDelete      SyncAction = "delete"
+	SyncUnsubscribe SyncAction = "unsubscribe"
+	SyncUnchanged   SyncAction = "unchanged"
+	SyncSkip        SyncAction = "skip"
+)
+
+// FieldChange is one field that differs between Loops and the desired state. Field is the API field name;
+// mailing lists appear as "mailingLists.<listId>".
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// SyncChange is one entry of the structured change log.
+type SyncChange struct {
+	Email     string        `json:"email,omitempty"`
+	UserID    string        `json:"userId,omitempty"`
+	ContactID string        `json:"contactId,omitempty"`
+	Action    SyncAction    `json:"action"`
+	Changes   []FieldChange `json:"changes,omitempty"`
+	Applied   bool          `json:"applied"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// SyncOptions configures SyncContacts.
+type SyncOptions struct {
+	DeletePolicy DeletePolicy
+	// DryRun computes the plan without calling any mutating endpoint.
+	DryRun bool
+	// OnChange, if set, is called with each change log entry as it is produced (including unchanged contacts).
+	OnChange func(SyncChange)
+}
+
+// SyncReport summarises a SyncContacts run. Changes lists every entry except unchanged contacts.
+type SyncReport struct {
+	Changes      []SyncChange
+	Created      int
+	Updated      int
+	Deleted      int
+	Unsubscribed int
+	Unchanged    int
+	Skipped      int
+	Failed       int
+}
+
+// SyncContacts reconciles Loops with src. For each desired contact it fetches the current state with FindContact,
+// computes the minimal set of changed fields and applies CreateContact, UpdateContact or DeleteContact (per
+// DeletePolicy). Contacts are processed sequentially; per-contact failures are recorded in the report and do not
+// stop the run. An error is returned only if src fails or ctx is cancelled.
+func (c *Client) SyncContacts(ctx context.Context, src ContactSource, opts SyncOptions) (*SyncReport, error) {
+	report := &SyncReport{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		want, err := src.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, fmt.Errorf("loops: contact source: %w", err)
+		}
+		change := c.syncContact(ctx, want, opts)
+		switch {
+		case change.Error != "":
+			report.Failed++
+		case change.Action == SyncCreate:
+			report.Created++
+		case change.Action == SyncUpdate:
+			report.Updated++
+		case change.Action == SyncDelete:
+			report.Deleted++
+		case change.Action == SyncUnsubscribe:
+			report.Unsubscribed++
+		case change.Action == SyncUnchanged:
+			report.Unchanged++
+		case change.Action == SyncSkip:
+			report.Skipped++
+		}
+		if change.Action != SyncUnchanged {
+			report.Changes = append(report.Changes, change)
+		}
+		if opts.OnChange != nil {
+			opts.OnChange(change)
+		}
+	}
+}
+
+func (c *Client) syncContact(ctx context.Context, want DesiredContact, opts SyncOptions) SyncChange {
+	change := SyncChange{Email: want.Email, UserID: want.UserID}
+	fail := func(err error) SyncChange {
+		change.Error = err.Error()
+		return change
+	}
+	found, err := c.FindContact(ctx, want.Email, identityUserID(want))
+	if err != nil {
+		return fail(err)
+	}
+	var current *Contact
+	if len(found) > 0 {
+		current = &found[0]
+		change.ContactID = current.ID
+	}
+
+	if want.Deleted {
+		switch {
+		case current == nil || opts.DeletePolicy == NeverDelete:
+			change.Action = SyncSkip
+			return change
+		case opts.DeletePolicy == HardDelete:
+			change.Action = SyncDelete
+			if opts.DryRun {
+				return change
+			}
+			if _, err := c.DeleteContact(ctx, identityDelete(want)); err != nil {
+				return fail(err)
+			}
+			change.Applied = true
+			return change
+		default:
+			if !current.Subscribed {
+				change.Action = SyncUnchanged
+				return change
+			}
+			change.Action = SyncUnsubscribe
+			change.Changes = []FieldChange{{Field: "subscribed", From: true, To: false}}
+			if opts.DryRun {
+				return change
+			}
+			if _, err := c.UpdateContact(ctx, &ContactUpdateRequest{Email: want.Email, UserID: identityUserID(want), Extra: map[string]interface{}{"subscribed": false}}); err != nil {
+				return fail(err)
+			}
+			change.Applied = true
+			return change
+		}
+	}
+
+	if current == nil {
+		change.Action = SyncCreate
+		change.Changes = diffContact(&Contact{}, want)
+		if want.Email == "" {
+			return fail(errors.New("email is required to create a contact"))
+		}
+		if opts.DryRun {
+			return change
+		}
+		req := &ContactRequest{Email: want.Email, UserID: want.UserID, Subscribed: want.Subscribed, MailingLists: want.MailingLists, Extra: want.Properties}
+		if want.FirstName != nil {
+			req.FirstName = *want.FirstName
+		}
+		if want.LastName != nil {
+			req.LastName = *want.LastName
+		}
+		if want.UserGroup != nil {
+			req.UserGroup = *want.UserGroup
+		}
+		out, err := c.CreateContact(ctx, req)
+		if err != nil {
+			return fail(err)
+		}
+		change.ContactID = out.ID
+		change.Applied = true
+		return change
+	}
+
+	change.Changes = diffContact(current, want)
+	if len(change.Changes) == 0 {
+		change.Action = SyncUnchanged
+		return change
+	}
+	change.Action = SyncUpdate
+	if opts.DryRun {
+		return change
+	}
+	// Changed fields go in Extra (merged into the body) so values can be cleared to "" despite omitempty tags.
+	req := &ContactUpdateRequest{Email: want.Email, UserID: identityUserID(want), Extra: make(map[string]interface{})}
+	for _, fc := range change.Changes {
+		if listID, ok := strings.CutPrefix(fc.Field, "mailingLists."); ok {
+			if req.MailingLists == nil {
+				req.MailingLists = make(map[string]bool)
+			}
+			req.MailingLists[listID] = fc.To.(bool)
+			continue
+		}
+		req.Extra[fc.Field] = fc.To
+	}
+	if _, err := c.UpdateContact(ctx, req); err != nil {
+		return fail(err)
+	}
+	change.Applied = true
+	return change
+}
+
+// identityUserID returns the userId to look the contact up by: only when there is no email, because the find,
+// delete and suppression endpoints accept exactly one identifier.
+func identityUserID(want DesiredContact) string {
+	if want.Email != "" {
+		return ""
+	}
+	return want.UserID
+}
+
+func identityDelete(want DesiredContact) *ContactDeleteRequest {
+	return &ContactDeleteRequest{Email: want.Email, UserID: identityUserID(want)}
+}
+
+// diffContact returns the managed fields of want that differ from current, in a stable order.
+func diffContact(current *Contact, want DesiredContact) []FieldChange {
+	var changes []FieldChange
+	str := func(field string, from *string, to *string) {
+		if to == nil {
+			return
+		}
+		f := ""
+		if from != nil {
+			f = *from
+		}
+		if f != *to {
+			changes = append(changes, FieldChange{Field: field, From: f, To: *to})
+		}
+	}
+	if want.UserID != "" {
+		str("userId", current.UserID, &want.UserID)
+	}
+	str("firstName", current.FirstName, want.FirstName)
+	str("lastName", current.LastName, want.LastName)
+	userGroup := current.UserGroup
+	str("userGroup", &userGroup, want.UserGroup)
+	if want.Subscribed != nil && (current.ID == "" || current.Subscribed != *want.Subscribed) {
+		changes = append(changes, FieldChange{Field: "subscribed", From: current.Subscribed, To: *want.Subscribed})
+	}
+	for _, k := range sortedKeys(want.Properties) {
+		from, ok := current.Extra[k]
+		if !ok || !sameJSON(from, want.Properties[k]) {
+			changes = append(changes, FieldChange{Field: k, From: from, To: want.Properties[k]})
+		}
+	}
+	lists := make([]string, 0, len(want.MailingLists))
+	for id := range want.MailingLists {
+		lists = append(lists, id)
+	}
+	sort.Strings(lists)
+	for _, id := range lists {
+		if current.MailingLists[id] != want.MailingLists[id] {
+			changes = append(changes, FieldChange{Field: "mailingLists." + id, From: current.MailingLists[id], To: want.MailingLists[id]})
+		}
+	}
+	return changes
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameJSON compares values by their JSON encoding, so 7 (int) and 7.0 (decoded float64) are equal.
+func sameJSON(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
@@ -0,0 +1,140 @@
+package loops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type syncCall struct {
+	method, path string
+	body         map[string]interface{}
+}
+
+// syncServer serves FindContact from existing (keyed by email) and records every mutating call.
+func syncServer(t *testing.T, existing map[string]string) (*Client, func() []syncCall) {
+	t.Helper()
+	var mu sync.Mutex
+	var calls []syncCall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/contacts/find" {
+			if c, ok := existing[r.URL.Query().Get("email")]; ok {
+				w.Write([]byte("[" + c + "]"))
+			} else {
+				w.Write([]byte("[]"))
+			}
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		calls = append(calls, syncCall{r.Method, r.URL.Path, body})
+		mu.Unlock()
+		w.Write([]byte(`{"success":true,"id":"new_id","message":"ok"}`))
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("key", WithBaseURL(server.URL)), func() []syncCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func TestClient_SyncContacts_MinimalDiff(t *testing.T) {
+	client, calls := syncServer(t, map[string]string{
+		"same@example.com":    `{"id":"c1","email":"same@example.com","firstName":"Sam","subscribed":true,"plan":"pro","mailingLists":{"l1":true}}`,
+		"changed@example.com": `{"id":"c2","email":"changed@example.com","firstName":"Old","subscribed":true,"plan":"free","seats":2,"mailingLists":{"l1":true}}`,
+	})
+	pro, sam, neo := "pro", "Sam", "New"
+	src := SliceContactSource([]DesiredContact{
+		{Email: "same@example.com", FirstName: &sam, Properties: map[string]interface{}{"plan": pro}, MailingLists: map[string]bool{"l1": true}},
+		{Email: "changed@example.com", FirstName: &neo, Properties: map[string]interface{}{"plan": pro, "seats": 2}, MailingLists: map[string]bool{"l1": false, "l2": true}},
+		{Email: "missing@example.com", FirstName: &neo, Properties: map[string]interface{}{"plan": pro}},
+	})
+	var log []SyncChange
+	report, err := client.SyncContacts(context.Background(), src, SyncOptions{OnChange: func(c SyncChange) { log = append(log, c) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged != 1 || report.Updated != 1 || report.Created != 1 || report.Failed != 0 {
+		t.Errorf("report: %+v", report)
+	}
+	if len(log) != 3 || len(report.Changes) != 2 {
+		t.Errorf("change log: %d entries, report: %d changes", len(log), len(report.Changes))
+	}
+
+	got := calls()
+	if len(got) != 2 {
+		t.Fatalf("calls: %+v", got)
+	}
+	update := got[0]
+	if update.method != http.MethodPut || update.path != "/contacts/update" {
+		t.Errorf("update call: %s %s", update.method, update.path)
+	}
+	if update.body["firstName"] != "New" || update.body["plan"] != "pro" {
+		t.Errorf("update body: %v", update.body)
+	}
+	if _, ok := update.body["seats"]; ok {
+		t.Error("unchanged property seats should not be sent")
+	}
+	lists, _ := update.body["mailingLists"].(map[string]interface{})
+	if lists["l1"] != false || lists["l2"] != true {
+		t.Errorf("mailingLists: %v", update.body["mailingLists"])
+	}
+	if create := got[1]; create.path != "/contacts/create" || create.body["email"] != "missing@example.com" || create.body["plan"] != "pro" {
+		t.Errorf("create call: %+v", create)
+	}
+}
+
+func TestClient_SyncContacts_DeletePoliciesAndDryRun(t *testing.T) {
+	existing := map[string]string{"gone@example.com": `{"id":"c9","email":"gone@example.com","subscribed":true}`}
+	src := func() ContactSource {
+		return SliceContactSource([]DesiredContact{{Email: "gone@example.com", Deleted: true}, {Email: "never@example.com", Deleted: true}})
+	}
+	ctx := context.Background()
+
+	client, calls := syncServer(t, existing)
+	report, _ := client.SyncContacts(ctx, src(), SyncOptions{})
+	if report.Skipped != 2 || len(calls()) != 0 {
+		t.Errorf("NeverDelete: %+v, calls %v", report, calls())
+	}
+
+	client, calls = syncServer(t, existing)
+	report, _ = client.SyncContacts(ctx, src(), SyncOptions{DeletePolicy: SoftUnsubscribe})
+	if report.Unsubscribed != 1 || len(calls()) != 1 || calls()[0].body["subscribed"] != false {
+		t.Errorf("SoftUnsubscribe: %+v, calls %v", report, calls())
+	}
+
+	client, calls = syncServer(t, existing)
+	report, _ = client.SyncContacts(ctx, src(), SyncOptions{DeletePolicy: HardDelete, DryRun: true})
+	if report.Deleted != 1 || len(calls()) != 0 || report.Changes[0].Applied {
+		t.Errorf("HardDelete dry run: %+v, calls %v", report, calls())
+	}
+
+	report, _ = client.SyncContacts(ctx, src(), SyncOptions{DeletePolicy: HardDelete})
+	if report.Deleted != 1 || len(calls()) != 1 || calls()[0].path != "/contacts/delete" || !report.Changes[0].Applied {
+		t.Errorf("HardDelete: %+v, calls %v", report, calls())
+	}
+}
+
+func TestContact_CustomPropertiesRoundTrip(t *testing.T) {
+	var c Contact
+	if err := json.Unmarshal([]byte(`{"id":"c1","email":"a@b.com","subscribed":true,"plan":"pro","seats":3}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.ID != "c1" || c.Extra["plan"] != "pro" || c.Extra["seats"] != 3.0 || len(c.Extra) != 2 {
+		t.Errorf("got %+v", c)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	json.Unmarshal(b, &m)
+	if m["plan"] != "pro" || m["email"] != "a@b.com" {
+		t.Errorf("marshalled: %s", b)
+	}
+}
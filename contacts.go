@@ -113,3 +113,33 @@ func (c *Client) DeleteContact(ctx context.Context, req *ContactDeleteRequest) (
 	}
 	return &out, nil
 }
+
+// contactFields are the JSON keys of Contact's standard fields; anything else in a contact object is a custom property.
+var contactFields = []string{"id", "email", "firstName", "lastName", "source", "subscribed", "userGroup", "userId", "mailingLists", "optInStatus"}
+
+// UnmarshalJSON decodes the standard fields and collects custom properties into Extra.
+func (c *Contact) UnmarshalJSON(data []byte) error {
+	type plain Contact
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for _, k := range contactFields {
+		delete(all, k)
+	}
+	if len(all) > 0 {
+		p.Extra = all
+	}
+	*c = Contact(p)
+	return nil
+}
+
+// MarshalJSON encodes the standard fields with Extra merged in, mirroring UnmarshalJSON.
+func (c Contact) MarshalJSON() ([]byte, error) {
+	type plain Contact
+	return mergeBody(plain(c), c.Extra)
+}
@@ -0,0 +1,211 @@
+package content
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+	"github.com/Whats-A-MattR/loops-go-sdk/lmx"
+)
+
+// CloneOptions configures CloneCampaign.
+type CloneOptions struct {
+	// Name is the name of the new campaign (default: the source campaign's name).
+	Name string
+}
+
+// CloneIssue is something CloneCampaign could not carry over exactly.
+type CloneIssue struct {
+	Kind    string `json:"kind"` // "component", "theme" or "lmx"
+	Ref     string `json:"ref"`  // source component or theme ID, if any
+	Message string `json:"message"`
+}
+
+// CloneResult describes a cloned campaign.
+type CloneResult struct {
+	CampaignID     string            `json:"campaignId"`
+	EmailMessageID string            `json:"emailMessageId"`
+	Components     map[string]string `json:"components,omitempty"` // source component ID -> destination component ID
+	Themes         map[string]string `json:"themes,omitempty"`     // source theme ID -> destination theme ID
+	Issues         []CloneIssue      `json:"issues,omitempty"`
+}
+
+// CloneCampaign reads a campaign and its email message through src and creates an equivalent draft through dst,
+// typically a client for another team or environment. The name, subject, preview text, sender fields and LMX
+// body are copied. <Component id="..." /> references are remapped to the destination component with the same
+// name; a component with no unique match is inlined. A <Style /> whose attributes equal a source theme's styles is
+// replaced with the styles of the destination theme with the same name; otherwise the styles are copied as they
+// are. Everything not carried over exactly is listed in CloneResult.Issues.
+func CloneCampaign(ctx context.Context, src, dst *loops.Client, campaignID string, opts CloneOptions) (*CloneResult, error) {
+	campaign, err := src.GetCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.EmailMessageID == nil || *campaign.EmailMessageID == "" {
+		return nil, &loops.APIError{StatusCode: 400, Message: fmt.Sprintf("campaign %s has no email message", campaignID)}
+	}
+	msg, err := src.GetEmailMessage(ctx, *campaign.EmailMessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CloneResult{Components: make(map[string]string), Themes: make(map[string]string)}
+	body, err := remapLMX(ctx, src, dst, msg.LMX, result)
+	if err != nil {
+		return nil, err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = campaign.Name
+	}
+	created, err := dst.CreateCampaign(ctx, &loops.CreateCampaignRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	result.CampaignID, result.EmailMessageID = created.CampaignID, created.EmailMessageID
+	req := &loops.UpdateEmailMessageRequest{
+		Subject:      msg.Subject,
+		PreviewText:  msg.PreviewText,
+		FromName:     msg.FromName,
+		FromEmail:    msg.FromEmail,
+		ReplyToEmail: msg.ReplyToEmail,
+		LMX:          body,
+	}
+	if created.EmailMessageContentRevisionID != nil {
+		req.ExpectedRevisionID = *created.EmailMessageContentRevisionID
+	}
+	if _, err := dst.UpdateEmailMessage(ctx, created.EmailMessageID, req); err != nil {
+		return result, fmt.Errorf("campaign %s created but its email message was not set: %w", created.CampaignID, err)
+	}
+	return result, nil
+}
+
+// remapLMX rewrites component and theme references in src for dst, recording mappings and issues in result.
+func remapLMX(ctx context.Context, src, dst *loops.Client, body string, result *CloneResult) (string, error) {
+	if body == "" {
+		return "", nil
+	}
+	doc, err := lmx.Parse(body)
+	if err != nil {
+		result.Issues = append(result.Issues, CloneIssue{Kind: "lmx", Message: fmt.Sprintf("body copied verbatim without remapping references: %v", err)})
+		return body, nil
+	}
+	hasComponents, hasStyle := false, false
+	walkElements(doc.Children, func(e *lmx.Element) {
+		hasComponents = hasComponents || e.Name == lmx.TagComponent
+		hasStyle = hasStyle || e.Name == lmx.TagStyle
+	})
+
+	var srcComponents, dstComponents []loops.Component
+	if hasComponents {
+		if srcComponents, err = listComponents(ctx, src); err != nil {
+			return "", err
+		}
+		if dstComponents, err = listComponents(ctx, dst); err != nil {
+			return "", err
+		}
+	}
+	var srcThemes, dstThemes []loops.Theme
+	if hasStyle {
+		if srcThemes, err = listThemes(ctx, src); err != nil {
+			return "", err
+		}
+		if dstThemes, err = listThemes(ctx, dst); err != nil {
+			return "", err
+		}
+	}
+
+	doc.Children = rewriteElements(doc.Children, func(e *lmx.Element) []lmx.Node {
+		switch e.Name {
+		case lmx.TagComponent:
+			return remapComponent(e, srcComponents, dstComponents, result)
+		case lmx.TagStyle:
+			remapTheme(e, srcThemes, dstThemes, result)
+		}
+		return []lmx.Node{e}
+	})
+	return doc.String(), nil
+}
+
+func remapComponent(e *lmx.Element, srcComponents, dstComponents []loops.Component, result *CloneResult) []lmx.Node {
+	id, _ := e.Get("id")
+	if mapped, ok := result.Components[id]; ok {
+		return []lmx.Node{e.Attr("id", mapped)}
+	}
+	var source *loops.Component
+	for i := range srcComponents {
+		if srcComponents[i].ComponentID == id {
+			source = &srcComponents[i]
+		}
+	}
+	if source == nil {
+		result.Issues = append(result.Issues, CloneIssue{Kind: "component", Ref: id, Message: "component not found in the source team; reference dropped"})
+		return nil
+	}
+	var matches []string
+	for _, c := range dstComponents {
+		if c.Name == source.Name {
+			matches = append(matches, c.ComponentID)
+		}
+	}
+	if len(matches) == 1 {
+		result.Components[id] = matches[0]
+		return []lmx.Node{e.Attr("id", matches[0])}
+	}
+	why := "no component"
+	if len(matches) > 1 {
+		why = fmt.Sprintf("%d components", len(matches))
+	}
+	result.Issues = append(result.Issues, CloneIssue{Kind: "component", Ref: id,
+		Message: fmt.Sprintf("%s named %q in the destination team; component body inlined", why, source.Name)})
+	return []lmx.Node{lmx.Raw(source.LMX)}
+}
+
+func remapTheme(e *lmx.Element, srcThemes, dstThemes []loops.Theme, result *CloneResult) {
+	styles := lmx.StylesOf(e)
+	var source *loops.Theme
+	for i := range srcThemes {
+		if srcThemes[i].Styles == styles {
+			source = &srcThemes[i]
+			break
+		}
+	}
+	if source == nil {
+		return // one-off styles, copied as they are
+	}
+	for _, t := range dstThemes {
+		if t.Name == source.Name {
+			result.Themes[source.ThemeID] = t.ThemeID
+			*e = *lmx.Style(t.Styles)
+			return
+		}
+	}
+	result.Issues = append(result.Issues, CloneIssue{Kind: "theme", Ref: source.ThemeID,
+		Message: fmt.Sprintf("no theme named %q in the destination team; styles copied inline", source.Name)})
+}
+
+// walkElements calls fn for every element in nodes, depth first.
+func walkElements(nodes []lmx.Node, fn func(*lmx.Element)) {
+	for _, n := range nodes {
+		if e, ok := n.(*lmx.Element); ok {
+			fn(e)
+			walkElements(e.Children, fn)
+		}
+	}
+}
+
+// rewriteElements replaces every element in nodes with the nodes fn returns for it, after rewriting its children.
+func rewriteElements(nodes []lmx.Node, fn func(*lmx.Element) []lmx.Node) []lmx.Node {
+	out := make([]lmx.Node, 0, len(nodes))
+	for _, n := range nodes {
+		e, ok := n.(*lmx.Element)
+		if !ok {
+			out = append(out, n)
+			continue
+		}
+		e.Children = rewriteElements(e.Children, fn)
+		out = append(out, fn(e)...)
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package content
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func TestCloneCampaign(t *testing.T) {
+	staging, src := newFakeLoops(t)
+	prod, dst := newFakeLoops(t)
+	brand := loops.ThemeStyles{BackgroundColor: "#fff", TextBaseFontSize: 16}
+	staging.themes = []loops.Theme{{ThemeID: "thm_s1", Name: "Brand", Styles: brand}, {ThemeID: "thm_s2", Name: "Dark", Styles: loops.ThemeStyles{BackgroundColor: "#000"}}}
+	prod.themes = []loops.Theme{{ThemeID: "thm_p1", Name: "Brand", Styles: loops.ThemeStyles{BackgroundColor: "#fafafa", TextBaseFontSize: 16}}}
+	staging.components = []loops.Component{
+		{ComponentID: "cmp_footer", Name: "Footer", LMX: "<Text>Footer</Text>"},
+		{ComponentID: "cmp_promo", Name: "Promo", LMX: "<Text>Promo</Text>"},
+	}
+	prod.components = []loops.Component{{ComponentID: "prd_footer", Name: "Footer", LMX: "<Text>Footer</Text>"}}
+
+	id := staging.addCampaign("Launch", "Sent", loops.EmailMessageResponse{
+		Subject: "It's here", PreviewText: "Finally", FromName: "Team", FromEmail: "team@x.test", ReplyToEmail: "help@x.test",
+		LMX: `<Style backgroundColor="#fff" textBaseFontSize="16" />
+<Text>Hi</Text>
+<Component id="cmp_promo" />
+<Section>
+  <Component id="cmp_footer" />
+</Section>
+<Component id="cmp_gone" />`,
+	})
+
+	res, err := CloneCampaign(context.Background(), src, dst, id, CloneOptions{Name: "Launch (prod)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod.campaigns[res.CampaignID].Name != "Launch (prod)" || prod.campaigns[res.CampaignID].Status != "Draft" {
+		t.Errorf("campaign: %+v", prod.campaigns[res.CampaignID])
+	}
+	msg := prod.message(res.CampaignID)
+	if msg.Subject != "It's here" || msg.PreviewText != "Finally" || msg.FromEmail != "team@x.test" || msg.ReplyToEmail != "help@x.test" {
+		t.Errorf("fields: %+v", msg)
+	}
+	want := `<Style backgroundColor="#fafafa" textBaseFontSize="16" />
+<Text>Hi</Text>
+<Text>Promo</Text>
+<Section>
+  <Component id="prd_footer" />
+</Section>`
+	if msg.LMX != want {
+		t.Errorf("LMX:\n%s\nwant:\n%s", msg.LMX, want)
+	}
+	if res.Components["cmp_footer"] != "prd_footer" || res.Themes["thm_s1"] != "thm_p1" {
+		t.Errorf("mappings: %v %v", res.Components, res.Themes)
+	}
+	var issues []string
+	for _, is := range res.Issues {
+		issues = append(issues, is.Kind+" "+is.Ref+": "+is.Message)
+	}
+	got := strings.Join(issues, "\n")
+	if !strings.Contains(got, `component cmp_promo: no component named "Promo"`) || !strings.Contains(got, "component cmp_gone: component not found") || len(issues) != 2 {
+		t.Errorf("issues:\n%s", got)
+	}
+	if len(staging.requests) != 2 { // GET campaign, GET email message
+		t.Errorf("source was modified: %v", staging.requests)
+	}
+}
+
+func TestCloneCampaign_UnmatchedThemeAndBadLMX(t *testing.T) {
+	staging, src := newFakeLoops(t)
+	_, dst := newFakeLoops(t)
+	staging.themes = []loops.Theme{{ThemeID: "thm_s2", Name: "Dark", Styles: loops.ThemeStyles{BackgroundColor: "#000"}}}
+	id := staging.addCampaign("A", "Draft", loops.EmailMessageResponse{Subject: "A", LMX: `<Style backgroundColor="#000" />`})
+	res, err := CloneCampaign(context.Background(), src, dst, id, CloneOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Kind != "theme" || res.Issues[0].Ref != "thm_s2" {
+		t.Errorf("issues: %+v", res.Issues)
+	}
+
+	id = staging.addCampaign("B", "Draft", loops.EmailMessageResponse{Subject: "B", LMX: "<Text>unclosed"})
+	res, err = CloneCampaign(context.Background(), src, dst, id, CloneOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Kind != "lmx" {
+		t.Errorf("issues: %+v", res.Issues)
+	}
+}
@@ -0,0 +1,241 @@
+package content
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// ComponentsStateFile records, in a components directory, which file holds each component and the content hash
+// at the last sync, so local and remote edits can be told apart.
+const ComponentsStateFile = ".loops-components.json"
+
+// ComponentStatus is the sync state of one component.
+type ComponentStatus string
+
+const (
+	ComponentInSync        ComponentStatus = "in-sync"
+	ComponentNew           ComponentStatus = "new"            // in Loops, never synced
+	ComponentRemoteChanged ComponentStatus = "remote-changed" // changed or renamed in Loops since the last sync
+	ComponentLocalChanged  ComponentStatus = "local-changed"  // file edited since the last sync
+	ComponentConflict      ComponentStatus = "conflict"       // both changed, or an untracked file differs
+	ComponentMissing       ComponentStatus = "missing"        // synced before, file deleted locally
+	ComponentDeleted       ComponentStatus = "deleted"        // synced before, gone from Loops
+	ComponentLocalOnly     ComponentStatus = "local-only"     // .lmx file that is not a Loops component
+)
+
+// CampaignRef identifies a campaign that uses a component or theme.
+type CampaignRef struct {
+	CampaignID string               `json:"campaignId"`
+	Name       string               `json:"name"`
+	Status     loops.CampaignStatus `json:"status"`
+}
+
+// ComponentSyncStatus is the result of SyncComponents for one component or file.
+type ComponentSyncStatus struct {
+	ComponentID string          `json:"componentId,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	File        string          `json:"file"`   // relative to the components directory
+	Status      ComponentStatus `json:"status"` // before the sync
+	Pulled      bool            `json:"pulled"` // the file was written, moved or removed
+	UsedBy      []CampaignRef   `json:"usedBy,omitempty"`
+}
+
+// ComponentSyncOptions configures SyncComponents.
+type ComponentSyncOptions struct {
+	// Pull writes new, changed, renamed and missing components to disk and removes unmodified files of deleted
+	// components. Without it SyncComponents only reports drift.
+	Pull bool
+	// Usage fills ComponentSyncStatus.UsedBy with the campaigns whose email messages reference each component.
+	// It fetches every campaign's email message.
+	Usage bool
+}
+
+type componentsState struct {
+	Components map[string]syncedComponent `json:"components"` // component ID -> last sync
+}
+
+type syncedComponent struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// SyncComponents mirrors the team's components to <dir>/<name>.lmx and reports drift between the files and
+// Loops. Drift is judged against the hashes recorded in ComponentsStateFile at the last pull. The API cannot
+// update components, so local edits are reported (local-changed, conflict) but never pushed or overwritten.
+// Files, including the state file, are only written when their content changes. Results are sorted by file name.
+func SyncComponents(ctx context.Context, client *loops.Client, dir string, opts ComponentSyncOptions) ([]ComponentSyncStatus, error) {
+	components, err := listComponents(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	statePath := filepath.Join(dir, ComponentsStateFile)
+	state := componentsState{Components: make(map[string]syncedComponent)}
+	if b, err := os.ReadFile(statePath); err == nil {
+		if err := json.Unmarshal(b, &state); err != nil {
+			return nil, fmt.Errorf("%s: %w", statePath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if state.Components == nil {
+		state.Components = make(map[string]syncedComponent)
+	}
+
+	var usage *Usage
+	if opts.Usage {
+		if usage, err = AnalyzeUsage(ctx, client); err != nil {
+			return nil, err
+		}
+	}
+
+	files := componentFileNames(components)
+	claimed := make(map[string]bool)
+	var results []ComponentSyncStatus
+	for _, c := range components {
+		file := files[c.ComponentID]
+		prev, synced := state.Components[c.ComponentID]
+		current := file
+		if synced {
+			current = prev.File
+		}
+		claimed[current], claimed[file] = true, true
+		local, err := os.ReadFile(filepath.Join(dir, current))
+		exists := err == nil
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		localHash, remoteHash := hashString(string(local)), hashString(c.LMX)
+
+		st := ComponentSyncStatus{ComponentID: c.ComponentID, Name: c.Name, File: file}
+		if usage != nil {
+			if e := usage.Component(c.ComponentID); e != nil && len(e.Campaigns) > 0 {
+				st.UsedBy = e.Campaigns
+			}
+		}
+		switch {
+		case !exists && !synced:
+			st.Status = ComponentNew
+		case !exists:
+			st.Status = ComponentMissing
+		case !synced && localHash == remoteHash:
+			st.Status = ComponentInSync
+		case !synced:
+			st.Status = ComponentConflict
+		case localHash == prev.SHA256 && (remoteHash != prev.SHA256 || current != file):
+			st.Status = ComponentRemoteChanged
+		case localHash == prev.SHA256 || localHash == remoteHash:
+			st.Status = ComponentInSync
+		case remoteHash == prev.SHA256:
+			st.Status, st.File = ComponentLocalChanged, current
+		default:
+			st.Status, st.File = ComponentConflict, current
+		}
+
+		if opts.Pull {
+			switch st.Status {
+			case ComponentNew, ComponentMissing, ComponentRemoteChanged:
+				if _, err := writeFileIfChanged(filepath.Join(dir, file), []byte(c.LMX)); err != nil {
+					return nil, err
+				}
+				if exists && current != file {
+					if err := os.Remove(filepath.Join(dir, current)); err != nil {
+						return nil, err
+					}
+				}
+				st.Pulled = true
+				state.Components[c.ComponentID] = syncedComponent{File: file, SHA256: remoteHash}
+			case ComponentInSync:
+				state.Components[c.ComponentID] = syncedComponent{File: current, SHA256: remoteHash}
+			}
+		}
+		results = append(results, st)
+	}
+
+	remote := make(map[string]bool, len(components))
+	for _, c := range components {
+		remote[c.ComponentID] = true
+	}
+	for id, prev := range state.Components {
+		if remote[id] {
+			continue
+		}
+		claimed[prev.File] = true
+		st := ComponentSyncStatus{ComponentID: id, File: prev.File, Status: ComponentDeleted}
+		if opts.Pull {
+			local, err := os.ReadFile(filepath.Join(dir, prev.File))
+			if err == nil && hashString(string(local)) == prev.SHA256 {
+				if err := os.Remove(filepath.Join(dir, prev.File)); err != nil {
+					return nil, err
+				}
+				st.Pulled = true
+			}
+			delete(state.Components, id)
+		}
+		results = append(results, st)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".lmx") && !claimed[e.Name()] {
+			results = append(results, ComponentSyncStatus{File: e.Name(), Status: ComponentLocalOnly})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].File < results[j].File })
+
+	if opts.Pull {
+		b, err := json.MarshalIndent(state, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writeFileIfChanged(statePath, append(b, '\n')); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// componentFileNames picks a file name per component: "<name>.lmx", or "<name>.<id>.lmx" when several
+// components share a name. Characters that are not safe in file names become "-".
+func componentFileNames(components []loops.Component) map[string]string {
+	byName := make(map[string]int)
+	for _, c := range components {
+		byName[strings.ToLower(safeFileName(c.Name))]++
+	}
+	names := make(map[string]string, len(components))
+	for _, c := range components {
+		base := safeFileName(c.Name)
+		if byName[strings.ToLower(base)] > 1 || base == "" {
+			base = strings.TrimPrefix(base+"."+safeFileName(c.ComponentID), ".")
+		}
+		names[c.ComponentID] = base + ".lmx"
+	}
+	return names
+}
+
+func safeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	return strings.TrimLeft(s, ".")
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package content
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func TestSyncComponents(t *testing.T) {
+	f, client := newFakeLoops(t)
+	f.components = []loops.Component{
+		{ComponentID: "c1", Name: "Footer", LMX: "<Text>Footer</Text>"},
+		{ComponentID: "c2", Name: "Header/Top", LMX: "<Text>Header</Text>"},
+		{ComponentID: "c3", Name: "Promo", LMX: "<Text>Promo</Text>"},
+		{ComponentID: "c4", Name: "Promo", LMX: "<Text>Promo 2</Text>"},
+	}
+	f.addCampaign("Launch", "Sent", loops.EmailMessageResponse{LMX: `<Component id="c1" /><Section><Component id="c1" /></Section>`})
+	f.addCampaign("Broken", "Draft", loops.EmailMessageResponse{LMX: `<Component id="c2">`})
+	dir := filepath.Join(t.TempDir(), "components")
+	ctx := context.Background()
+
+	res, err := SyncComponents(ctx, client, dir, ComponentSyncOptions{Pull: true, Usage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]ComponentStatus{}
+	for _, r := range res {
+		got[r.File] = r.Status
+		if !r.Pulled {
+			t.Errorf("%s not pulled", r.File)
+		}
+	}
+	want := map[string]ComponentStatus{"Footer.lmx": ComponentNew, "Header-Top.lmx": ComponentNew, "Promo.c3.lmx": ComponentNew, "Promo.c4.lmx": ComponentNew}
+	if len(got) != len(want) {
+		t.Fatalf("statuses: %v", got)
+	}
+	for file, status := range want {
+		if got[file] != status {
+			t.Errorf("%s: %s, want %s", file, got[file], status)
+		}
+	}
+	if res[0].File != "Footer.lmx" || len(res[0].UsedBy) != 1 || res[0].UsedBy[0].Name != "Launch" || res[0].UsedBy[0].Status != "Sent" {
+		t.Errorf("usage: %+v", res[0].UsedBy)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "Footer.lmx")); string(b) != "<Text>Footer</Text>" {
+		t.Errorf("Footer.lmx: %q", b)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, ComponentsStateFile+".tmp*")); len(tmp) > 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+
+	// Drift: Footer edited locally, Header changed remotely, Promo c3 changed on both sides (it keeps its file),
+	// c4 renamed, and a stray file added.
+	os.WriteFile(filepath.Join(dir, "Footer.lmx"), []byte("<Text>Footer (local)</Text>"), 0o644)
+	os.WriteFile(filepath.Join(dir, "Promo.c3.lmx"), []byte("<Text>mine</Text>"), 0o644)
+	os.WriteFile(filepath.Join(dir, "Notes.lmx"), []byte("<Text>draft</Text>"), 0o644)
+	f.components = []loops.Component{
+		{ComponentID: "c1", Name: "Footer", LMX: "<Text>Footer</Text>"},
+		{ComponentID: "c2", Name: "Header/Top", LMX: "<Text>Header v2</Text>"},
+		{ComponentID: "c3", Name: "Promo", LMX: "<Text>theirs</Text>"},
+		{ComponentID: "c4", Name: "Banner", LMX: "<Text>Promo 2</Text>"},
+	}
+	check := func(pull bool, want map[string]ComponentStatus) {
+		t.Helper()
+		res, err := SyncComponents(ctx, client, dir, ComponentSyncOptions{Pull: pull})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]ComponentStatus{}
+		for _, r := range res {
+			got[r.File] = r.Status
+		}
+		if len(got) != len(want) {
+			t.Errorf("statuses: %v, want %v", got, want)
+		}
+		for file, status := range want {
+			if got[file] != status {
+				t.Errorf("%s: %s, want %s", file, got[file], status)
+			}
+		}
+	}
+	drift := map[string]ComponentStatus{
+		"Footer.lmx": ComponentLocalChanged, "Header-Top.lmx": ComponentRemoteChanged, "Promo.c3.lmx": ComponentConflict,
+		"Banner.lmx": ComponentRemoteChanged, "Notes.lmx": ComponentLocalOnly,
+	}
+	check(false, drift)
+	check(true, drift)
+	if b, _ := os.ReadFile(filepath.Join(dir, "Header-Top.lmx")); string(b) != "<Text>Header v2</Text>" {
+		t.Errorf("remote change not pulled: %q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "Footer.lmx")); string(b) != "<Text>Footer (local)</Text>" {
+		t.Errorf("local edit overwritten: %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Promo.c4.lmx")); !os.IsNotExist(err) {
+		t.Errorf("renamed component's old file kept: %v", err)
+	}
+
+	// After the pull only the local edits remain; deleting a component removes its unmodified file.
+	f.components = f.components[:3]
+	check(true, map[string]ComponentStatus{
+		"Footer.lmx": ComponentLocalChanged, "Header-Top.lmx": ComponentInSync, "Promo.c3.lmx": ComponentConflict,
+		"Banner.lmx": ComponentDeleted, "Notes.lmx": ComponentLocalOnly,
+	})
+	if _, err := os.Stat(filepath.Join(dir, "Banner.lmx")); !os.IsNotExist(err) {
+		t.Errorf("deleted component's file kept: %v", err)
+	}
+}
+
+func TestSyncComponents_UnchangedKeepsFiles(t *testing.T) {
+	f, client := newFakeLoops(t)
+	f.components = []loops.Component{{ComponentID: "c1", Name: "Footer", LMX: "<Text>Footer</Text>"}}
+	dir := t.TempDir()
+	if _, err := SyncComponents(context.Background(), client, dir, ComponentSyncOptions{Pull: true}); err != nil {
+		t.Fatal(err)
+	}
+	old := ageFiles(t, dir)
+	res, err := SyncComponents(context.Background(), client, dir, ComponentSyncOptions{Pull: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Status != ComponentInSync || res[0].Pulled {
+		t.Errorf("second sync: %+v", res)
+	}
+	if touched := touchedFiles(t, dir, old); len(touched) != 0 {
+		t.Errorf("unchanged sync touched %v", touched)
+	}
+}
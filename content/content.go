@@ -0,0 +1,90 @@
+// Package content manages Loops content as files: campaign drafts declared in a directory tree and deployed
+// with the loops client.
+//
+// A campaign lives in its own directory with a manifest (campaign.json) and an LMX body (body.lmx):
+//
+//	campaigns/
+//	  .loops-state.json
+//	  welcome/
+//	    campaign.json   {"key": "welcome", "name": "Welcome", "subject": "Hi {firstName}", ...}
+//	    body.lmx
+//
+// PlanDeploy compares the files with the team's campaigns and ApplyDeploy creates or updates the drafts.
+package content
+
+import (
+	"context"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// pageSize is the page size used when walking paginated list endpoints (the API maximum).
+const pageSize = 50
+
+// listCampaigns returns every campaign, following pagination cursors.
+func listCampaigns(ctx context.Context, client *loops.Client) ([]loops.CampaignListItem, error) {
+	var all []loops.CampaignListItem
+	cursor := ""
+	for {
+		page, err := client.ListCampaigns(ctx, pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.Pagination.NextCursor == nil || *page.Pagination.NextCursor == "" {
+			return all, nil
+		}
+		cursor = *page.Pagination.NextCursor
+	}
+}
+
+// listThemes returns every theme, following pagination cursors.
+func listThemes(ctx context.Context, client *loops.Client) ([]loops.Theme, error) {
+	var all []loops.Theme
+	cursor := ""
+	for {
+		page, err := client.ListThemes(ctx, pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.Pagination.NextCursor == nil || *page.Pagination.NextCursor == "" {
+			return all, nil
+		}
+		cursor = *page.Pagination.NextCursor
+	}
+}
+
+// listComponents returns every component, following pagination cursors.
+func listComponents(ctx context.Context, client *loops.Client) ([]loops.Component, error) {
+	var all []loops.Component
+	cursor := ""
+	for {
+		page, err := client.ListComponents(ctx, pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.Pagination.NextCursor == nil || *page.Pagination.NextCursor == "" {
+			return all, nil
+		}
+		cursor = *page.Pagination.NextCursor
+	}
+}
+
+// listTransactionals returns every published transactional email, following pagination cursors.
+func listTransactionals(ctx context.Context, client *loops.Client) ([]loops.TransactionalEmail, error) {
+	var all []loops.TransactionalEmail
+	cursor := ""
+	for {
+		page, err := client.ListTransactionals(ctx, pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.Pagination.NextCursor == nil || *page.Pagination.NextCursor == "" {
+			return all, nil
+		}
+		cursor = *page.Pagination.NextCursor
+	}
+}
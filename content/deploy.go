@@ -0,0 +1,401 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+	"github.com/Whats-A-MattR/loops-go-sdk/internal/atomicfile"
+	"github.com/Whats-A-MattR/loops-go-sdk/lmx"
+)
+
+// File names used in a campaign tree.
+const (
+	ManifestFile = "campaign.json"     // campaign manifest in each campaign directory
+	BodyFile     = "body.lmx"          // LMX body in each campaign directory
+	StateFile    = ".loops-state.json" // manifest key to campaign ID map at the root of the tree
+)
+
+// Manifest describes a campaign draft. Key identifies the campaign across deploys and renames; it defaults to
+// the directory name. Empty optional fields are left as they are in Loops, because the API cannot clear them.
+type Manifest struct {
+	Key          string `json:"key,omitempty"`
+	Name         string `json:"name"`
+	Subject      string `json:"subject"`
+	PreviewText  string `json:"previewText,omitempty"`
+	FromName     string `json:"fromName"`
+	FromEmail    string `json:"fromEmail"`
+	ReplyToEmail string `json:"replyToEmail,omitempty"`
+}
+
+// CampaignSpec is a campaign loaded from disk.
+type CampaignSpec struct {
+	Dir      string
+	Manifest Manifest
+	LMX      string
+}
+
+// LoadCampaign reads ManifestFile and BodyFile from dir. The body must parse as LMX.
+func LoadCampaign(dir string) (*CampaignSpec, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	spec := &CampaignSpec{Dir: dir}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec.Manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	if spec.Manifest.Key == "" {
+		spec.Manifest.Key = filepath.Base(dir)
+	}
+	if spec.Manifest.Name == "" {
+		return nil, fmt.Errorf("%s: name is required", filepath.Join(dir, ManifestFile))
+	}
+	body, err := os.ReadFile(filepath.Join(dir, BodyFile))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := lmx.Parse(string(body)); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, BodyFile), err)
+	}
+	spec.LMX = string(body)
+	return spec, nil
+}
+
+// LoadCampaigns loads every subdirectory of root that contains a ManifestFile, sorted by directory name.
+// Manifest keys must be unique.
+func LoadCampaigns(root string) ([]*CampaignSpec, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var specs []*CampaignSpec
+	seen := make(map[string]string)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		if _, err := os.Stat(filepath.Join(dir, ManifestFile)); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		spec, err := LoadCampaign(dir)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[spec.Manifest.Key]; ok {
+			return nil, fmt.Errorf("campaign key %q is used by both %s and %s", spec.Manifest.Key, other, dir)
+		}
+		seen[spec.Manifest.Key] = dir
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// State maps manifest keys to the campaign IDs they were deployed to. Commit it with the tree so deploys from
+// any checkout update the same drafts.
+type State struct {
+	Campaigns map[string]string `json:"campaigns"`
+}
+
+// LoadState reads a state file. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{Campaigns: make(map[string]string)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Campaigns == nil {
+		s.Campaigns = make(map[string]string)
+	}
+	return s, nil
+}
+
+// Save writes the state as indented JSON (keys sorted, so the file diffs cleanly). The file is replaced
+// atomically, so an interrupted save leaves the previous state intact.
+func (s *State) Save(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// Action is what a deploy does with one campaign.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+	ActionRefuse    Action = "refuse" // the matching campaign cannot be changed; see CampaignPlan.Reason
+)
+
+// CampaignPlan is the planned change for one campaign spec.
+type CampaignPlan struct {
+	Key            string            `json:"key"`
+	Action         Action            `json:"action"`
+	CampaignID     string            `json:"campaignId,omitempty"`
+	EmailMessageID string            `json:"emailMessageId,omitempty"`
+	Reason         string            `json:"reason,omitempty"`
+	Changes        []lmx.FieldChange `json:"changes,omitempty"`
+	Diff           string            `json:"diff,omitempty"` // unified diff of the formatted LMX body
+
+	spec     *CampaignSpec
+	revision string // contentRevisionId the diff was computed against
+}
+
+// DeployPlan is the result of PlanDeploy, in spec order.
+type DeployPlan struct {
+	Campaigns []*CampaignPlan `json:"campaigns"`
+}
+
+// Count returns the number of campaigns with action a.
+func (p *DeployPlan) Count(a Action) int {
+	n := 0
+	for _, c := range p.Campaigns {
+		if c.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteTo writes a human-readable summary of the plan with field changes and body diffs.
+func (p *DeployPlan) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, c := range p.Campaigns {
+		switch c.Action {
+		case ActionCreate:
+			fmt.Fprintf(&b, "+ create %s\n", c.Key)
+		case ActionUpdate:
+			fmt.Fprintf(&b, "~ update %s (%s)\n", c.Key, c.CampaignID)
+		case ActionUnchanged:
+			fmt.Fprintf(&b, "= unchanged %s (%s)\n", c.Key, c.CampaignID)
+		case ActionRefuse:
+			fmt.Fprintf(&b, "! refuse %s: %s\n", c.Key, c.Reason)
+		}
+		if c.Action == ActionCreate && c.Reason != "" {
+			fmt.Fprintf(&b, "    (%s)\n", c.Reason)
+		}
+		for _, ch := range c.Changes {
+			fmt.Fprintf(&b, "    %s: %q -> %q\n", ch.Field, ch.Old, ch.New)
+		}
+		for _, line := range splitLines(c.Diff) {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	fmt.Fprintf(&b, "%d to create, %d to update, %d unchanged, %d refused\n",
+		p.Count(ActionCreate), p.Count(ActionUpdate), p.Count(ActionUnchanged), p.Count(ActionRefuse))
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// PlanDeploy works out what ApplyDeploy would do for specs. A spec is matched to a campaign by the ID recorded
+// in state for its key, or else by a unique campaign with the manifest name (adopting drafts made in the UI).
+// Campaigns that are not drafts, or have no email message, are refused. It makes no changes.
+func PlanDeploy(ctx context.Context, client *loops.Client, specs []*CampaignSpec, state *State) (*DeployPlan, error) {
+	campaigns, err := listCampaigns(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]loops.CampaignListItem, len(campaigns))
+	byName := make(map[string][]loops.CampaignListItem)
+	for _, c := range campaigns {
+		byID[c.CampaignID] = c
+		byName[c.Name] = append(byName[c.Name], c)
+	}
+	tracked := make(map[string]string) // campaign ID -> key, to stop two keys adopting one campaign
+	for key, id := range state.Campaigns {
+		tracked[id] = key
+	}
+
+	plan := &DeployPlan{}
+	for _, spec := range specs {
+		key := spec.Manifest.Key
+		cp := &CampaignPlan{Key: key, spec: spec}
+		plan.Campaigns = append(plan.Campaigns, cp)
+
+		campaign, found := loops.CampaignListItem{}, false
+		if id, ok := state.Campaigns[key]; ok {
+			if campaign, found = byID[id]; !found {
+				cp.Reason = fmt.Sprintf("tracked campaign %s no longer exists", id)
+			}
+		} else {
+			var untracked []loops.CampaignListItem
+			for _, c := range byName[spec.Manifest.Name] {
+				if _, ok := tracked[c.CampaignID]; !ok {
+					untracked = append(untracked, c)
+				}
+			}
+			switch len(untracked) {
+			case 0:
+			case 1:
+				campaign, found = untracked[0], true
+				tracked[campaign.CampaignID] = key
+			default:
+				cp.Action = ActionRefuse
+				cp.Reason = fmt.Sprintf("%d campaigns are named %q; record the right one in %s", len(untracked), spec.Manifest.Name, StateFile)
+				continue
+			}
+		}
+		if !found {
+			cp.Action = ActionCreate
+			cp.Changes = fieldChanges(spec.Manifest, loops.EmailMessageResponse{}, "")
+			cp.Diff = unifiedDiff("/dev/null", filepath.Join(spec.Dir, BodyFile), "", formatLMX(spec.LMX))
+			continue
+		}
+
+		cp.CampaignID = campaign.CampaignID
+		if campaign.Status != loops.CampaignDraft {
+			cp.Action = ActionRefuse
+			cp.Reason = fmt.Sprintf("campaign %s is %s, not a draft", campaign.CampaignID, campaign.Status)
+			continue
+		}
+		if campaign.EmailMessageID == nil || *campaign.EmailMessageID == "" {
+			cp.Action = ActionRefuse
+			cp.Reason = fmt.Sprintf("campaign %s has no email message", campaign.CampaignID)
+			continue
+		}
+		cp.EmailMessageID = *campaign.EmailMessageID
+		msg, err := client.GetEmailMessage(ctx, cp.EmailMessageID)
+		if err != nil {
+			return nil, fmt.Errorf("campaign %s: %w", key, err)
+		}
+		if msg.ContentRevisionID != nil {
+			cp.revision = *msg.ContentRevisionID
+		}
+		cp.Changes = fieldChanges(spec.Manifest, *msg, campaign.Name)
+		cp.Diff = unifiedDiff("loops:"+cp.EmailMessageID, filepath.Join(spec.Dir, BodyFile), formatLMX(msg.LMX), formatLMX(spec.LMX))
+		cp.Action = ActionUnchanged
+		if len(cp.Changes) > 0 || cp.Diff != "" {
+			cp.Action = ActionUpdate
+		}
+	}
+	return plan, nil
+}
+
+// messageUpdate returns the email message fields to send for cp and the campaign's new name ("" if unchanged).
+// A new campaign gets every managed field; an update sends only the fields that changed, and the body only
+// when its formatted LMX differs.
+func messageUpdate(cp *CampaignPlan) (*loops.UpdateEmailMessageRequest, string) {
+	m := cp.spec.Manifest
+	if cp.Action == ActionCreate {
+		return &loops.UpdateEmailMessageRequest{Subject: m.Subject, PreviewText: m.PreviewText, FromName: m.FromName,
+			FromEmail: m.FromEmail, ReplyToEmail: m.ReplyToEmail, LMX: cp.spec.LMX}, ""
+	}
+	req, rename := &loops.UpdateEmailMessageRequest{}, ""
+	for _, ch := range cp.Changes {
+		switch ch.Field {
+		case "name":
+			rename = ch.New
+		case "subject":
+			req.Subject = ch.New
+		case "previewText":
+			req.PreviewText = ch.New
+		case "fromName":
+			req.FromName = ch.New
+		case "fromEmail":
+			req.FromEmail = ch.New
+		case "replyToEmail":
+			req.ReplyToEmail = ch.New
+		}
+	}
+	if cp.Diff != "" {
+		req.LMX = cp.spec.LMX
+	}
+	return req, rename
+}
+
+// fieldChanges lists manifest fields that differ from the campaign name and email message. Empty manifest
+// fields other than the name are not managed.
+func fieldChanges(m Manifest, msg loops.EmailMessageResponse, name string) []lmx.FieldChange {
+	fields := []struct{ field, old, new string }{
+		{"name", name, m.Name},
+		{"subject", msg.Subject, m.Subject},
+		{"previewText", msg.PreviewText, m.PreviewText},
+		{"fromName", msg.FromName, m.FromName},
+		{"fromEmail", msg.FromEmail, m.FromEmail},
+		{"replyToEmail", msg.ReplyToEmail, m.ReplyToEmail},
+	}
+	var changes []lmx.FieldChange
+	for _, f := range fields {
+		if f.new != "" && f.new != f.old {
+			changes = append(changes, lmx.FieldChange{Field: f.field, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
+
+// formatLMX normalises LMX for diffing, falling back to the source if it does not parse.
+func formatLMX(src string) string {
+	if out, err := lmx.Format(src); err == nil {
+		return out
+	}
+	return src
+}
+
+// ApplyDeploy carries out plan: it creates missing campaigns and updates changed drafts, recording new campaign
+// IDs in state as it goes (save state even when it returns an error). Updates send only the fields that changed,
+// and leave the email message alone when only the name did. Email messages are updated with the revision the
+// plan was computed against, so a draft edited since PlanDeploy fails with a 409 instead of being overwritten.
+// Refused campaigns are skipped and reported in the returned error.
+func ApplyDeploy(ctx context.Context, client *loops.Client, plan *DeployPlan, state *State) error {
+	var refused []string
+	for _, cp := range plan.Campaigns {
+		switch cp.Action {
+		case ActionRefuse:
+			refused = append(refused, cp.Key)
+			continue
+		case ActionCreate:
+			created, err := client.CreateCampaign(ctx, &loops.CreateCampaignRequest{Name: cp.spec.Manifest.Name})
+			if err != nil {
+				return fmt.Errorf("campaign %s: %w", cp.Key, err)
+			}
+			cp.CampaignID, cp.EmailMessageID = created.CampaignID, created.EmailMessageID
+			if created.EmailMessageContentRevisionID != nil {
+				cp.revision = *created.EmailMessageContentRevisionID
+			}
+			state.Campaigns[cp.Key] = cp.CampaignID
+		case ActionUpdate:
+			state.Campaigns[cp.Key] = cp.CampaignID
+		default:
+			state.Campaigns[cp.Key] = cp.CampaignID
+			continue
+		}
+		req, rename := messageUpdate(cp)
+		if rename != "" {
+			if _, err := client.UpdateCampaign(ctx, cp.CampaignID, &loops.UpdateCampaignRequest{Name: rename}); err != nil {
+				return fmt.Errorf("campaign %s: %w", cp.Key, err)
+			}
+		}
+		if *req == (loops.UpdateEmailMessageRequest{}) {
+			continue
+		}
+		req.ExpectedRevisionID = cp.revision
+		if _, err := client.UpdateEmailMessage(ctx, cp.EmailMessageID, req); err != nil {
+			return fmt.Errorf("campaign %s: %w", cp.Key, err)
+		}
+	}
+	if len(refused) > 0 {
+		sort.Strings(refused)
+		return fmt.Errorf("refused to deploy %d campaign(s): %s", len(refused), strings.Join(refused, ", "))
+	}
+	return nil
+}
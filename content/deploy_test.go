@@ -0,0 +1,216 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// writeCampaign writes a campaign directory under root.
+func writeCampaign(t *testing.T, root, dir, manifest, body string) {
+	t.Helper()
+	path := filepath.Join(root, dir)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, ManifestFile), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, BodyFile), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	f, client := newFakeLoops(t)
+	adopted := f.addCampaign("Launch", "Draft", loops.EmailMessageResponse{Subject: "Old", FromName: "Team", FromEmail: "team@x.test",
+		LMX: "<Text>Hello</Text>\n<Text>Bye</Text>"})
+	sent := f.addCampaign("Newsletter", "Sent", loops.EmailMessageResponse{Subject: "News"})
+
+	root := t.TempDir()
+	writeCampaign(t, root, "launch", `{"name":"Launch","subject":"New","fromName":"Team","fromEmail":"team@x.test"}`,
+		"<Text>Hello</Text>\n<Text>See you</Text>\n")
+	writeCampaign(t, root, "newsletter", `{"name":"Newsletter","subject":"News 2","fromName":"Team","fromEmail":"team@x.test"}`, "<Text>Hi</Text>")
+	writeCampaign(t, root, "welcome", `{"key":"welcome-v1","name":"Welcome","subject":"Hi","fromName":"Team","fromEmail":"team@x.test"}`, "<Text>Welcome</Text>")
+
+	specs, err := LoadCampaigns(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ := LoadState(filepath.Join(root, StateFile))
+	plan, err := PlanDeploy(context.Background(), client, specs, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	plan.WriteTo(&out)
+	for _, want := range []string{
+		"~ update launch (" + adopted + ")",
+		`    subject: "Old" -> "New"`,
+		"    -<Text>Bye</Text>",
+		"    +<Text>See you</Text>",
+		"! refuse newsletter: campaign " + sent + " is Sent, not a draft",
+		"+ create welcome-v1",
+		"1 to create, 1 to update, 0 unchanged, 1 refused",
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("plan output missing %q:\n%s", want, out.String())
+		}
+	}
+	if len(f.requests) != 1 { // one GET of the adopted campaign's message
+		t.Errorf("planning made changes: %v", f.requests)
+	}
+
+	err = ApplyDeploy(context.Background(), client, plan, state)
+	if err == nil || !strings.Contains(err.Error(), "newsletter") {
+		t.Errorf("expected refusal error, got %v", err)
+	}
+	if m := f.message(adopted); m.Subject != "New" || !strings.Contains(m.LMX, "See you") {
+		t.Errorf("launch not updated: %+v", m)
+	}
+	if f.message(sent).Subject != "News" {
+		t.Error("sent campaign was modified")
+	}
+	created := state.Campaigns["welcome-v1"]
+	if created == "" || state.Campaigns["launch"] != adopted || f.message(created).LMX != "<Text>Welcome</Text>" {
+		t.Errorf("state: %v", state.Campaigns)
+	}
+
+	// With saved state, campaigns are tracked by key, so renaming one in its manifest renames the campaign.
+	statePath := filepath.Join(root, StateFile)
+	if err := state.Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+	if tmp, _ := filepath.Glob(statePath + ".tmp*"); len(tmp) > 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+	os.RemoveAll(filepath.Join(root, "newsletter"))
+	writeCampaign(t, root, "welcome", `{"key":"welcome-v1","name":"Welcome (v1)","subject":"Hi","fromName":"Team","fromEmail":"team@x.test"}`, "<Text>Welcome</Text>")
+	specs, _ = LoadCampaigns(root)
+	state, _ = LoadState(statePath)
+	plan, err = PlanDeploy(context.Background(), client, specs, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Count(ActionUnchanged) != 1 || plan.Count(ActionUpdate) != 1 || plan.Campaigns[1].Changes[0].Field != "name" {
+		t.Fatalf("second plan: %+v", plan.Campaigns)
+	}
+	if err := ApplyDeploy(context.Background(), client, plan, state); err != nil {
+		t.Fatal(err)
+	}
+	if f.campaigns[created].Name != "Welcome (v1)" {
+		t.Errorf("rename not applied: %q", f.campaigns[created].Name)
+	}
+}
+
+func TestDeploy_StaleRevision(t *testing.T) {
+	f, client := newFakeLoops(t)
+	id := f.addCampaign("Launch", "Draft", loops.EmailMessageResponse{Subject: "Old"})
+	root := t.TempDir()
+	writeCampaign(t, root, "launch", `{"name":"Launch","subject":"New","fromName":"T","fromEmail":"t@x.test"}`, "<Text>Hi</Text>")
+	specs, _ := LoadCampaigns(root)
+	state, _ := LoadState(filepath.Join(root, StateFile))
+	plan, err := PlanDeploy(context.Background(), client, specs, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev := "edited-in-ui"
+	f.message(id).ContentRevisionID = &rev
+	var apiErr *loops.APIError
+	if err := ApplyDeploy(context.Background(), client, plan, state); !errors.As(err, &apiErr) || apiErr.StatusCode != 409 {
+		t.Errorf("expected 409, got %v", err)
+	}
+}
+
+func TestDeploy_SendsOnlyChangedFields(t *testing.T) {
+	f, client := newFakeLoops(t)
+	id := f.addCampaign("Launch", "Draft", loops.EmailMessageResponse{Subject: "Hi", FromName: "T", FromEmail: "t@x.test",
+		LMX: "<Text>Hi</Text>"})
+	root := t.TempDir()
+	deploy := func(manifest string) {
+		t.Helper()
+		writeCampaign(t, root, "launch", manifest, "<Text>Hi</Text>\n")
+		specs, _ := LoadCampaigns(root)
+		state, _ := LoadState(filepath.Join(root, StateFile))
+		plan, err := PlanDeploy(context.Background(), client, specs, state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ApplyDeploy(context.Background(), client, plan, state); err != nil {
+			t.Fatal(err)
+		}
+		if err := state.Save(filepath.Join(root, StateFile)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first deploy adopts the campaign by name and changes nothing; with the state saved, only the campaign
+	// name differs on the second, so the email message is not touched.
+	deploy(`{"name":"Launch","subject":"Hi","fromName":"T","fromEmail":"t@x.test"}`)
+	deploy(`{"name":"Launch v2","subject":"Hi","fromName":"T","fromEmail":"t@x.test"}`)
+	if f.campaigns[id].Name != "Launch v2" || len(f.messageUpdates) != 0 {
+		t.Fatalf("name only: name %q, message updates %+v", f.campaigns[id].Name, f.messageUpdates)
+	}
+
+	deploy(`{"name":"Launch v2","subject":"Hello","fromName":"T","fromEmail":"t@x.test"}`)
+	want := loops.UpdateEmailMessageRequest{ExpectedRevisionID: "rev_1", Subject: "Hello"}
+	if len(f.messageUpdates) != 1 || f.messageUpdates[0] != want {
+		t.Errorf("subject only: %+v", f.messageUpdates)
+	}
+}
+
+func TestLoadCampaigns_Errors(t *testing.T) {
+	tests := []struct{ name, manifest, body, want string }{
+		{"unknown field", `{"name":"A","subjct":"x"}`, "<Text>x</Text>", "unknown field"},
+		{"no name", `{"subject":"x"}`, "<Text>x</Text>", "name is required"},
+		{"bad body", `{"name":"A"}`, "<Text>x", "body.lmx: lmx: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeCampaign(t, root, "a", tt.manifest, tt.body)
+			if _, err := LoadCampaigns(root); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	root := t.TempDir()
+	writeCampaign(t, root, "a", `{"key":"k","name":"A"}`, "")
+	writeCampaign(t, root, "b", `{"key":"k","name":"B"}`, "")
+	if _, err := LoadCampaigns(root); err == nil || !strings.Contains(err.Error(), `"k" is used by both`) {
+		t.Errorf("duplicate keys: %v", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	want := `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got := unifiedDiff("a", "b", a, b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := unifiedDiff("a", "b", a, a); got != "" {
+		t.Errorf("equal inputs: %q", got)
+	}
+}
@@ -0,0 +1,299 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+	"github.com/Whats-A-MattR/loops-go-sdk/internal/atomicfile"
+)
+
+// ExportVersion is the layout version recorded in ExportIndexFile. It changes when the tree layout does.
+const ExportVersion = 1
+
+// ExportIndexFile is the file at the root of an export with the layout version and the updatedAt of every
+// exported campaign, used by incremental exports.
+const ExportIndexFile = "export.json"
+
+// exportDirs are the directories Export owns; files in them that no longer correspond to a resource are removed.
+var exportDirs = []string{"campaigns", "components", "themes", "transactionals"}
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// Incremental skips fetching the email message of campaigns whose updatedAt matches the previous export and
+	// keeps their files. It assumes editing an email message bumps its campaign's updatedAt; run a full export
+	// from time to time if that does not hold for your team.
+	Incremental bool
+}
+
+// ExportResult lists what Export changed, as slash-separated paths relative to the export directory.
+type ExportResult struct {
+	Written   []string `json:"written"`
+	Removed   []string `json:"removed"`
+	Unchanged int      `json:"unchanged"`
+	Skipped   int      `json:"skipped"` // campaigns not refetched in incremental mode
+}
+
+// exportIndex is the content of ExportIndexFile.
+type exportIndex struct {
+	Version   int                        `json:"version"`
+	UpdatedAt map[string]loops.Timestamp `json:"updatedAt"` // "campaigns/<id>" -> campaign updatedAt
+}
+
+// emailMessageFile is message.json: an email message without its LMX body, which is written to body.lmx.
+type emailMessageFile struct {
+	EmailMessageID    string          `json:"emailMessageId"`
+	Subject           string          `json:"subject"`
+	PreviewText       string          `json:"previewText"`
+	FromName          string          `json:"fromName"`
+	FromEmail         string          `json:"fromEmail"`
+	ReplyToEmail      string          `json:"replyToEmail"`
+	ContentRevisionID *string         `json:"contentRevisionId"`
+	UpdatedAt         loops.Timestamp `json:"updatedAt"`
+}
+
+// componentFile is components/<id>.json; the body is written to components/<id>.lmx.
+type componentFile struct {
+	ComponentID string `json:"componentId"`
+	Name        string `json:"name"`
+}
+
+// Export writes the team's content to dir as a deterministic tree of indented JSON and LMX files, suitable for
+// committing to git:
+//
+//	export.json                     layout version and campaign updatedAt index
+//	campaigns/<id>/campaign.json    ListCampaigns item
+//	campaigns/<id>/message.json     email message fields
+//	campaigns/<id>/body.lmx         email message LMX
+//	components/<id>.json, <id>.lmx
+//	themes/<id>.json
+//	transactionals/<id>.json
+//	lists.json                      mailing lists, sorted by ID
+//	properties.json                 contact properties, sorted by key
+//
+// Files are only rewritten when their content changes, and files of deleted resources are removed.
+func Export(ctx context.Context, client *loops.Client, dir string, opts ExportOptions) (*ExportResult, error) {
+	x := &exporter{dir: dir, keep: make(map[string]bool), res: &ExportResult{}}
+	prev := exportIndex{}
+	if b, err := os.ReadFile(filepath.Join(dir, ExportIndexFile)); err == nil {
+		if err := json.Unmarshal(b, &prev); err != nil {
+			return nil, fmt.Errorf("%s: %w", ExportIndexFile, err)
+		}
+		if prev.Version != ExportVersion {
+			prev = exportIndex{} // different layout: export everything
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	index := exportIndex{Version: ExportVersion, UpdatedAt: make(map[string]loops.Timestamp)}
+
+	campaigns, err := listCampaigns(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range campaigns {
+		if err := checkID(c.CampaignID); err != nil {
+			return nil, err
+		}
+		base := "campaigns/" + c.CampaignID
+		if err := x.writeJSON(base+"/campaign.json", c); err != nil {
+			return nil, err
+		}
+		index.UpdatedAt[base] = c.UpdatedAt
+		if c.EmailMessageID == nil || *c.EmailMessageID == "" {
+			continue
+		}
+		if opts.Incremental && c.UpdatedAt.String() != "" && prev.UpdatedAt[base].Equal(c.UpdatedAt) && x.exists(base+"/message.json") {
+			x.keep[base+"/message.json"], x.keep[base+"/body.lmx"] = true, true
+			x.res.Skipped++
+			continue
+		}
+		msg, err := client.GetEmailMessage(ctx, *c.EmailMessageID)
+		if err != nil {
+			return nil, fmt.Errorf("campaign %s: %w", c.CampaignID, err)
+		}
+		err = x.writeJSON(base+"/message.json", emailMessageFile{
+			EmailMessageID: msg.EmailMessageID, Subject: msg.Subject, PreviewText: msg.PreviewText,
+			FromName: msg.FromName, FromEmail: msg.FromEmail, ReplyToEmail: msg.ReplyToEmail,
+			ContentRevisionID: msg.ContentRevisionID, UpdatedAt: msg.UpdatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := x.write(base+"/body.lmx", []byte(msg.LMX)); err != nil {
+			return nil, err
+		}
+	}
+
+	components, err := listComponents(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range components {
+		if err := checkID(c.ComponentID); err != nil {
+			return nil, err
+		}
+		if err := x.writeJSON("components/"+c.ComponentID+".json", componentFile{c.ComponentID, c.Name}); err != nil {
+			return nil, err
+		}
+		if err := x.write("components/"+c.ComponentID+".lmx", []byte(c.LMX)); err != nil {
+			return nil, err
+		}
+	}
+
+	themes, err := listThemes(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range themes {
+		if err := checkID(t.ThemeID); err != nil {
+			return nil, err
+		}
+		if err := x.writeJSON("themes/"+t.ThemeID+".json", t); err != nil {
+			return nil, err
+		}
+	}
+
+	transactionals, err := listTransactionals(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transactionals {
+		if err := checkID(t.ID); err != nil {
+			return nil, err
+		}
+		if err := x.writeJSON("transactionals/"+t.ID+".json", t); err != nil {
+			return nil, err
+		}
+	}
+
+	lists, err := client.GetLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].ID < lists[j].ID })
+	if err := x.writeJSON("lists.json", lists); err != nil {
+		return nil, err
+	}
+	properties, err := client.ListContactProperties(ctx, "all")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(properties, func(i, j int) bool { return properties[i].Key < properties[j].Key })
+	if err := x.writeJSON("properties.json", properties); err != nil {
+		return nil, err
+	}
+
+	if err := x.writeJSON(ExportIndexFile, index); err != nil {
+		return nil, err
+	}
+	if err := x.prune(); err != nil {
+		return nil, err
+	}
+	sort.Strings(x.res.Written)
+	sort.Strings(x.res.Removed)
+	return x.res, nil
+}
+
+type exporter struct {
+	dir  string
+	keep map[string]bool // relative paths written or kept by this export
+	res  *ExportResult
+}
+
+func (x *exporter) exists(rel string) bool {
+	_, err := os.Stat(filepath.Join(x.dir, filepath.FromSlash(rel)))
+	return err == nil
+}
+
+// write writes data to rel unless the file already has exactly that content.
+func (x *exporter) write(rel string, data []byte) error {
+	x.keep[rel] = true
+	written, err := writeFileIfChanged(filepath.Join(x.dir, filepath.FromSlash(rel)), data)
+	if err != nil {
+		return err
+	}
+	if written {
+		x.res.Written = append(x.res.Written, rel)
+	} else {
+		x.res.Unchanged++
+	}
+	return nil
+}
+
+// writeFileIfChanged atomically writes data to path, creating its directory, unless the file already has exactly
+// that content, so unchanged files keep their modification time. It reports whether it wrote.
+func writeFileIfChanged(path string, data []byte) (bool, error) {
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	return true, atomicfile.WriteFile(path, data, 0o644)
+}
+
+func (x *exporter) writeJSON(rel string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return x.write(rel, append(b, '\n'))
+}
+
+// prune removes files in exportDirs that this export did not write or keep, and directories left empty.
+func (x *exporter) prune() error {
+	for _, d := range exportDirs {
+		root := filepath.Join(x.dir, d)
+		var dirs []string
+		err := filepath.WalkDir(root, func(p string, e fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && p == root {
+				return filepath.SkipDir
+			}
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(x.dir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if e.IsDir() {
+				dirs = append(dirs, p)
+				return nil
+			}
+			if x.keep[rel] {
+				return nil
+			}
+			x.res.Removed = append(x.res.Removed, rel)
+			return os.Remove(p)
+		})
+		if err != nil {
+			return err
+		}
+		// Deepest first, so emptied campaign directories go before their parent is checked.
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if entries, err := os.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+				os.Remove(dirs[i])
+			}
+		}
+	}
+	return nil
+}
+
+// checkID rejects IDs that cannot be used as a single path element.
+func checkID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) || path.Clean(id) != id {
+		return fmt.Errorf("cannot export resource with ID %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package content
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func TestExport(t *testing.T) {
+	f, client := newFakeLoops(t)
+	id := f.addCampaign("Launch", "Draft", loops.EmailMessageResponse{Subject: "Hi", LMX: "<Text>Hi</Text>"})
+	f.campaigns[id].UpdatedAt = loops.NewTimestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	other := f.addCampaign("Old", "Sent", loops.EmailMessageResponse{Subject: "Old", LMX: "<Text>Old</Text>"})
+	f.components = []loops.Component{{ComponentID: "cmp_footer", Name: "Footer", LMX: "<Text>Footer</Text>"}}
+	f.themes = []loops.Theme{{ThemeID: "thm_1", Name: "Brand", Styles: loops.ThemeStyles{BackgroundColor: "#fff"}}}
+	f.transactionals = []loops.TransactionalEmail{{ID: "tx_1", Name: "Reset", LastUpdated: loops.NewTimestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), DataVariables: []string{"url"}}}
+	f.lists = []loops.MailingList{{ID: "l2", Name: "B"}, {ID: "l1", Name: "A"}}
+	f.properties = []loops.ContactProperty{{Key: "plan", Label: "Plan", Type: "string"}, {Key: "firstName", Label: "First", Type: "string"}}
+
+	dir := t.TempDir()
+	res, err := Export(context.Background(), client, dir, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"campaigns/" + id + "/body.lmx", "campaigns/" + id + "/campaign.json", "campaigns/" + id + "/message.json",
+		"campaigns/" + other + "/body.lmx", "campaigns/" + other + "/campaign.json", "campaigns/" + other + "/message.json",
+		"components/cmp_footer.json", "components/cmp_footer.lmx", "export.json", "lists.json", "properties.json",
+		"themes/thm_1.json", "transactionals/tx_1.json",
+	}
+	if !reflect.DeepEqual(res.Written, want) {
+		t.Errorf("written:\n%v\nwant:\n%v", res.Written, want)
+	}
+	read := func(rel string) string {
+		b, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		return string(b)
+	}
+	if got := read("campaigns/" + id + "/body.lmx"); got != "<Text>Hi</Text>" {
+		t.Errorf("body: %q", got)
+	}
+	if got := read("lists.json"); strings.Index(got, `"l1"`) > strings.Index(got, `"l2"`) {
+		t.Errorf("lists not sorted:\n%s", got)
+	}
+
+	// A second full export rewrites nothing.
+	res, err = Export(context.Background(), client, dir, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Written) != 0 || res.Unchanged != len(want) {
+		t.Errorf("second export: %+v", res)
+	}
+
+	// Incremental: a campaign with an unchanged updatedAt is not refetched, and deleted resources are removed.
+	delete(f.campaigns, other)
+	f.components = nil
+	f.requests = nil
+	f.message(id).LMX = "<Text>changed, but campaign updatedAt was not bumped</Text>"
+	res, err = Export(context.Background(), client, dir, ExportOptions{Incremental: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Skipped != 1 || len(f.requests) != 0 {
+		t.Errorf("incremental export fetched %v (skipped %d)", f.requests, res.Skipped)
+	}
+	if got := read("campaigns/" + id + "/body.lmx"); got != "<Text>Hi</Text>" {
+		t.Errorf("skipped body was rewritten: %q", got)
+	}
+	wantRemoved := []string{
+		"campaigns/" + other + "/body.lmx", "campaigns/" + other + "/campaign.json", "campaigns/" + other + "/message.json",
+		"components/cmp_footer.json", "components/cmp_footer.lmx",
+	}
+	if !reflect.DeepEqual(res.Removed, wantRemoved) || !reflect.DeepEqual(res.Written, []string{"export.json"}) {
+		t.Errorf("incremental result: %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "campaigns", other)); !os.IsNotExist(err) {
+		t.Errorf("empty campaign directory left behind: %v", err)
+	}
+}
+
+func TestExport_IncrementalKeepsUnchangedFiles(t *testing.T) {
+	f, client := newFakeLoops(t)
+	id := f.addCampaign("Launch", "Draft", loops.EmailMessageResponse{Subject: "Hi", LMX: "<Text>Hi</Text>"})
+	f.campaigns[id].UpdatedAt = loops.NewTimestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	f.components = []loops.Component{{ComponentID: "cmp_footer", Name: "Footer", LMX: "<Text>Footer</Text>"}}
+	dir := t.TempDir()
+	if _, err := Export(context.Background(), client, dir, ExportOptions{Incremental: true}); err != nil {
+		t.Fatal(err)
+	}
+	old := ageFiles(t, dir)
+	res, err := Export(context.Background(), client, dir, ExportOptions{Incremental: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if touched := touchedFiles(t, dir, old); len(touched) != 0 || len(res.Written) != 0 {
+		t.Errorf("unchanged export touched %v (written %v)", touched, res.Written)
+	}
+}
+
+// ageFiles sets the modification time of every file under dir to a fixed time in the past and returns it.
+func ageFiles(t *testing.T, dir string) time.Time {
+	t.Helper()
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := filepath.WalkDir(dir, func(p string, e os.DirEntry, err error) error {
+		if err != nil || e.IsDir() {
+			return err
+		}
+		return os.Chtimes(p, old, old)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return old
+}
+
+// touchedFiles lists the files under dir whose modification time is no longer old.
+func touchedFiles(t *testing.T, dir string, old time.Time) []string {
+	t.Helper()
+	var touched []string
+	err := filepath.WalkDir(dir, func(p string, e os.DirEntry, err error) error {
+		if err != nil || e.IsDir() {
+			return err
+		}
+		if fi, err := e.Info(); err != nil || !fi.ModTime().Equal(old) {
+			touched = append(touched, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return touched
+}
+
+func TestCheckID(t *testing.T) {
+	for _, id := range []string{"", ".", "..", "a/b", `a\b`} {
+		if checkID(id) == nil {
+			t.Errorf("checkID(%q) accepted", id)
+		}
+	}
+	if err := checkID("cmp_123"); err != nil {
+		t.Error(err)
+	}
+}
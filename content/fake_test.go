@@ -0,0 +1,165 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// fakeLoops is an in-memory Loops team serving the content endpoints this package uses.
+type fakeLoops struct {
+	mu             sync.Mutex
+	campaigns      map[string]*loops.CampaignListItem
+	messages       map[string]*loops.EmailMessageResponse
+	themes         []loops.Theme
+	components     []loops.Component
+	transactionals []loops.TransactionalEmail
+	lists          []loops.MailingList
+	properties     []loops.ContactProperty
+	requests       []string // "METHOD /path" of every request except lists
+	messageUpdates []loops.UpdateEmailMessageRequest
+	nextID         int
+}
+
+func newFakeLoops(t *testing.T) (*fakeLoops, *loops.Client) {
+	t.Helper()
+	f := &fakeLoops{
+		campaigns: make(map[string]*loops.CampaignListItem),
+		messages:  make(map[string]*loops.EmailMessageResponse),
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, loops.NewClient("key", loops.WithBaseURL(server.URL))
+}
+
+// addCampaign stores a campaign with an email message and returns the campaign ID.
+func (f *fakeLoops) addCampaign(name string, status loops.CampaignStatus, msg loops.EmailMessageResponse) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addCampaignLocked(name, status, msg)
+}
+
+func (f *fakeLoops) addCampaignLocked(name string, status loops.CampaignStatus, msg loops.EmailMessageResponse) string {
+	f.nextID++
+	id, msgID := fmt.Sprintf("cmp_%d", f.nextID), fmt.Sprintf("em_%d", f.nextID)
+	rev := "rev_1"
+	msg.Success, msg.EmailMessageID, msg.CampaignID, msg.ContentRevisionID = true, msgID, &id, &rev
+	f.messages[msgID] = &msg
+	f.campaigns[id] = &loops.CampaignListItem{CampaignID: id, EmailMessageID: &msgID, Name: name, Subject: msg.Subject, Status: status}
+	return id
+}
+
+func (f *fakeLoops) message(campaignID string) *loops.EmailMessageResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.messages[*f.campaigns[campaignID].EmailMessageID]
+}
+
+func (f *fakeLoops) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method != http.MethodGet || (len(parts) == 2 && parts[0] != "contacts") {
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	}
+	reply := func(v interface{}) { json.NewEncoder(w).Encode(v) }
+	fail := func(status int, msg string) {
+		w.WriteHeader(status)
+		reply(map[string]interface{}{"success": false, "message": msg})
+	}
+	switch {
+	case parts[0] == "campaigns" && len(parts) == 1 && r.Method == http.MethodGet:
+		ids := make([]string, 0, len(f.campaigns))
+		for id := range f.campaigns {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		out := loops.ListCampaignsResponse{Success: true}
+		// Serve one campaign per page to exercise cursors.
+		start := 0
+		if c := r.URL.Query().Get("cursor"); c != "" {
+			fmt.Sscan(c, &start)
+		}
+		if start < len(ids) {
+			out.Data = append(out.Data, *f.campaigns[ids[start]])
+		}
+		if start+1 < len(ids) {
+			next := fmt.Sprint(start + 1)
+			out.Pagination.NextCursor = &next
+		}
+		reply(out)
+	case parts[0] == "campaigns" && len(parts) == 1 && r.Method == http.MethodPost:
+		var req loops.CreateCampaignRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		id := f.addCampaignLocked(req.Name, loops.CampaignDraft, loops.EmailMessageResponse{})
+		c := f.campaigns[id]
+		w.WriteHeader(http.StatusCreated)
+		reply(loops.CreateCampaignResponse{Success: true, CampaignID: id, Name: c.Name, Status: c.Status,
+			EmailMessageID: *c.EmailMessageID, EmailMessageContentRevisionID: f.messages[*c.EmailMessageID].ContentRevisionID})
+	case parts[0] == "campaigns" && len(parts) == 2:
+		c, ok := f.campaigns[parts[1]]
+		if !ok {
+			fail(http.StatusNotFound, "Campaign not found.")
+			return
+		}
+		if r.Method == http.MethodPost {
+			var req loops.UpdateCampaignRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			c.Name = req.Name
+		}
+		reply(loops.CampaignResponse{Success: true, CampaignID: c.CampaignID, Name: c.Name, Status: c.Status, EmailMessageID: c.EmailMessageID})
+	case parts[0] == "email-messages" && len(parts) == 2:
+		m, ok := f.messages[parts[1]]
+		if !ok {
+			fail(http.StatusNotFound, "Email message not found.")
+			return
+		}
+		if r.Method == http.MethodPost {
+			var req loops.UpdateEmailMessageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			f.messageUpdates = append(f.messageUpdates, req)
+			if f.campaigns[*m.CampaignID].Status != loops.CampaignDraft {
+				fail(http.StatusConflict, "Campaign is not in draft status.")
+				return
+			}
+			if req.ExpectedRevisionID != "" && req.ExpectedRevisionID != *m.ContentRevisionID {
+				fail(http.StatusConflict, "Stale contentRevisionId.")
+				return
+			}
+			set := func(dst *string, v string) {
+				if v != "" {
+					*dst = v
+				}
+			}
+			set(&m.Subject, req.Subject)
+			set(&m.PreviewText, req.PreviewText)
+			set(&m.FromName, req.FromName)
+			set(&m.FromEmail, req.FromEmail)
+			set(&m.ReplyToEmail, req.ReplyToEmail)
+			set(&m.LMX, req.LMX)
+			rev := *m.ContentRevisionID + "+"
+			m.ContentRevisionID = &rev
+			f.campaigns[*m.CampaignID].Subject = m.Subject
+		}
+		reply(m)
+	case parts[0] == "themes" && len(parts) == 1:
+		reply(loops.ListThemesResponse{Success: true, Data: f.themes})
+	case parts[0] == "components" && len(parts) == 1:
+		reply(loops.ListComponentsResponse{Success: true, Data: f.components})
+	case parts[0] == "transactional":
+		reply(loops.ListTransactionalsResponse{Data: f.transactionals})
+	case parts[0] == "lists":
+		reply(f.lists)
+	case r.URL.Path == "/contacts/properties":
+		reply(f.properties)
+	default:
+		fail(http.StatusNotFound, "not found")
+	}
+}
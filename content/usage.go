@@ -0,0 +1,206 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+	"github.com/Whats-A-MattR/loops-go-sdk/lmx"
+)
+
+// UsageEntry lists the campaigns that use a component or theme.
+type UsageEntry struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	IsDefault bool          `json:"isDefault,omitempty"` // default theme
+	Campaigns []CampaignRef `json:"campaigns"`
+}
+
+// Usage is a reverse index from components and themes to the campaigns whose email messages use them.
+type Usage struct {
+	Components []UsageEntry `json:"components"` // every component, by name, used or not
+	Themes     []UsageEntry `json:"themes"`     // every theme, by name, used or not
+	// UnknownComponents are referenced component IDs that ListComponents does not return.
+	UnknownComponents []UsageEntry  `json:"unknownComponents"`
+	CustomStyles      []CampaignRef `json:"customStyles"` // a <Style /> that matches no theme
+	Unparsed          []CampaignRef `json:"unparsed"`     // LMX that does not parse, so was not searched
+}
+
+// Component returns the entry for a component ID, or nil.
+func (u *Usage) Component(id string) *UsageEntry {
+	if e := findEntry(u.Components, id); e != nil {
+		return e
+	}
+	return findEntry(u.UnknownComponents, id)
+}
+
+// Theme returns the entry for a theme ID, or nil.
+func (u *Usage) Theme(id string) *UsageEntry { return findEntry(u.Themes, id) }
+
+func findEntry(entries []UsageEntry, id string) *UsageEntry {
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// AnalyzeUsage walks every campaign (ListCampaigns), parses its email message's LMX and indexes the components
+// it references with <Component id="..." /> and the theme it uses. A theme is recognized when a <Style /> carries
+// exactly that theme's styles; a body without <Style /> is counted for the default theme.
+func AnalyzeUsage(ctx context.Context, client *loops.Client) (*Usage, error) {
+	components, err := listComponents(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	themes, err := listThemes(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	campaigns, err := listCampaigns(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &Usage{Components: []UsageEntry{}, Themes: []UsageEntry{}, UnknownComponents: []UsageEntry{},
+		CustomStyles: []CampaignRef{}, Unparsed: []CampaignRef{}}
+	componentIndex := make(map[string]int)
+	for _, c := range components {
+		componentIndex[c.ComponentID] = len(u.Components)
+		u.Components = append(u.Components, UsageEntry{ID: c.ComponentID, Name: c.Name, Campaigns: []CampaignRef{}})
+	}
+	defaultTheme := -1
+	for i, t := range themes {
+		if t.IsDefault {
+			defaultTheme = i
+		}
+		u.Themes = append(u.Themes, UsageEntry{ID: t.ThemeID, Name: t.Name, IsDefault: t.IsDefault, Campaigns: []CampaignRef{}})
+	}
+	unknown := make(map[string]int)
+
+	for _, c := range campaigns {
+		if c.EmailMessageID == nil || *c.EmailMessageID == "" {
+			continue
+		}
+		msg, err := client.GetEmailMessage(ctx, *c.EmailMessageID)
+		if err != nil {
+			return nil, fmt.Errorf("campaign %s: %w", c.CampaignID, err)
+		}
+		ref := CampaignRef{CampaignID: c.CampaignID, Name: c.Name, Status: c.Status}
+		doc, err := lmx.Parse(msg.LMX)
+		if err != nil {
+			u.Unparsed = append(u.Unparsed, ref)
+			continue
+		}
+		seen, styled := make(map[string]bool), false
+		themeUsed := make(map[int]bool)
+		walkElements(doc.Children, func(e *lmx.Element) {
+			switch e.Name {
+			case lmx.TagComponent:
+				id, _ := e.Get("id")
+				if seen[id] {
+					return
+				}
+				seen[id] = true
+				if i, ok := componentIndex[id]; ok {
+					u.Components[i].Campaigns = append(u.Components[i].Campaigns, ref)
+					return
+				}
+				if _, ok := unknown[id]; !ok {
+					unknown[id] = len(u.UnknownComponents)
+					u.UnknownComponents = append(u.UnknownComponents, UsageEntry{ID: id})
+				}
+				u.UnknownComponents[unknown[id]].Campaigns = append(u.UnknownComponents[unknown[id]].Campaigns, ref)
+			case lmx.TagStyle:
+				styled = true
+				styles := lmx.StylesOf(e)
+				for i, t := range themes {
+					if t.Styles == styles {
+						if !themeUsed[i] {
+							themeUsed[i] = true
+							u.Themes[i].Campaigns = append(u.Themes[i].Campaigns, ref)
+						}
+						return
+					}
+				}
+				u.CustomStyles = append(u.CustomStyles, ref)
+			}
+		})
+		if !styled && defaultTheme >= 0 {
+			u.Themes[defaultTheme].Campaigns = append(u.Themes[defaultTheme].Campaigns, ref)
+		}
+	}
+
+	for _, entries := range [][]UsageEntry{u.Components, u.Themes, u.UnknownComponents} {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Name != entries[j].Name {
+				return entries[i].Name < entries[j].Name
+			}
+			return entries[i].ID < entries[j].ID
+		})
+		for _, e := range entries {
+			sortRefs(e.Campaigns)
+		}
+	}
+	sortRefs(u.CustomStyles)
+	sortRefs(u.Unparsed)
+	return u, nil
+}
+
+func sortRefs(refs []CampaignRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].CampaignID < refs[j].CampaignID
+	})
+}
+
+// WriteReport writes a plain-text report: each component and theme with the campaigns that use it, then
+// unknown component references, campaigns with custom styles and campaigns that could not be parsed.
+func (u *Usage) WriteReport(w io.Writer) error {
+	var b strings.Builder
+	section := func(kind string, entries []UsageEntry) {
+		for _, e := range entries {
+			label := e.Name
+			if e.IsDefault {
+				label += ", default"
+			}
+			if label == "" {
+				label = "unknown"
+			}
+			fmt.Fprintf(&b, "%s %s (%s): %s\n", kind, e.ID, label, plural(len(e.Campaigns), "campaign"))
+			writeRefs(&b, e.Campaigns)
+		}
+	}
+	section("component", u.Components)
+	section("component", u.UnknownComponents)
+	section("theme", u.Themes)
+	if len(u.CustomStyles) > 0 {
+		fmt.Fprintf(&b, "custom styles: %s\n", plural(len(u.CustomStyles), "campaign"))
+		writeRefs(&b, u.CustomStyles)
+	}
+	if len(u.Unparsed) > 0 {
+		fmt.Fprintf(&b, "not parsed: %s\n", plural(len(u.Unparsed), "campaign"))
+		writeRefs(&b, u.Unparsed)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeRefs(b *strings.Builder, refs []CampaignRef) {
+	for _, r := range refs {
+		fmt.Fprintf(b, "  %s  %s  %s\n", r.CampaignID, r.Status, r.Name)
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,77 @@
+package content
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+	"github.com/Whats-A-MattR/loops-go-sdk/lmx"
+)
+
+func TestAnalyzeUsage(t *testing.T) {
+	f, client := newFakeLoops(t)
+	brand := loops.ThemeStyles{BackgroundColor: "#fff", BodyXPadding: 24}
+	f.themes = []loops.Theme{
+		{ThemeID: "t1", Name: "Default", IsDefault: true},
+		{ThemeID: "t2", Name: "Brand", Styles: brand},
+	}
+	f.components = []loops.Component{{ComponentID: "c1", Name: "Footer"}, {ComponentID: "c2", Name: "Header"}}
+	style := (&lmx.Document{Children: []lmx.Node{lmx.Style(brand)}}).String()
+	launch := f.addCampaign("Launch", "Sent", loops.EmailMessageResponse{LMX: style + `<Component id="c1" /><Section><Component id="c1" /></Section>`})
+	f.addCampaign("Welcome", "Draft", loops.EmailMessageResponse{LMX: `<Component id="c1" /><Component id="gone" />`})
+	f.addCampaign("Custom", "Draft", loops.EmailMessageResponse{LMX: `<Style bodyColor="#123456" />`})
+	broken := f.addCampaign("Broken", "Draft", loops.EmailMessageResponse{LMX: `<Component id="c2">`})
+
+	u, err := AnalyzeUsage(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := func(refs []CampaignRef) string {
+		var s []string
+		for _, r := range refs {
+			s = append(s, r.Name+":"+string(r.Status))
+		}
+		return strings.Join(s, ",")
+	}
+	for _, tc := range []struct {
+		entry *UsageEntry
+		want  string
+	}{
+		{u.Component("c1"), "Launch:Sent,Welcome:Draft"},
+		{u.Component("c2"), ""},
+		{u.Component("gone"), "Welcome:Draft"},
+		{u.Theme("t1"), "Welcome:Draft"},
+		{u.Theme("t2"), "Launch:Sent"},
+	} {
+		if tc.entry == nil || names(tc.entry.Campaigns) != tc.want {
+			t.Errorf("%+v, want %q", tc.entry, tc.want)
+		}
+	}
+	if u.Component("nope") != nil {
+		t.Error("unknown ID found")
+	}
+	if u.Components[0].Name != "Footer" || u.Themes[0].Name != "Brand" {
+		t.Errorf("order: %+v %+v", u.Components, u.Themes)
+	}
+	if names(u.CustomStyles) != "Custom:Draft" || len(u.Unparsed) != 1 || u.Unparsed[0].CampaignID != broken {
+		t.Errorf("custom %+v, unparsed %+v", u.CustomStyles, u.Unparsed)
+	}
+
+	var b strings.Builder
+	if err := u.WriteReport(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"component c1 (Footer): 2 campaigns\n  " + launch + "  Sent  Launch\n",
+		"component c2 (Header): 0 campaigns\n",
+		"component gone (unknown): 1 campaign\n",
+		"theme t1 (Default, default): 1 campaign\n",
+		"custom styles: 1 campaign\n",
+		"not parsed: 1 campaign\n  " + broken + "  Draft  Broken\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -59,8 +59,8 @@ type Theme struct {
 	Name      string      `json:"name"`
 	Styles    ThemeStyles `json:"styles"`
 	IsDefault bool        `json:"isDefault"`
-	CreatedAt string      `json:"createdAt"`
-	UpdatedAt string      `json:"updatedAt"`
+	CreatedAt Timestamp   `json:"createdAt"`
+	UpdatedAt Timestamp   `json:"updatedAt"`
 }
 
 // ListThemesResponse is the 200 response for GET /themes.
@@ -77,8 +77,8 @@ type ThemeResponse struct {
 	Name      string      `json:"name"`
 	Styles    ThemeStyles `json:"styles"`
 	IsDefault bool        `json:"isDefault"`
-	CreatedAt string      `json:"createdAt"`
-	UpdatedAt string      `json:"updatedAt"`
+	CreatedAt Timestamp   `json:"createdAt"`
+	UpdatedAt Timestamp   `json:"updatedAt"`
 }
 
 // ThemeFailureResponse is used for theme request failures.
@@ -117,13 +117,13 @@ type ComponentFailureResponse struct {
 
 // CampaignListItem is a single campaign returned from GET /campaigns.
 type CampaignListItem struct {
-	CampaignID     string  `json:"campaignId"`
-	EmailMessageID *string `json:"emailMessageId"`
-	Name           string  `json:"name"`
-	Subject        string  `json:"subject"`
-	Status         string  `json:"status"`
-	CreatedAt      string  `json:"createdAt"`
-	UpdatedAt      string  `json:"updatedAt"`
+	CampaignID     string         `json:"campaignId"`
+	EmailMessageID *string        `json:"emailMessageId"`
+	Name           string         `json:"name"`
+	Subject        string         `json:"subject"`
+	Status         CampaignStatus `json:"status"`
+	CreatedAt      Timestamp      `json:"createdAt"`
+	UpdatedAt      Timestamp      `json:"updatedAt"`
 }
 
 // ListCampaignsResponse is the 200 response for GET /campaigns.
@@ -140,14 +140,14 @@ type CreateCampaignRequest struct {
 
 // CreateCampaignResponse is the 201 response for POST /campaigns.
 type CreateCampaignResponse struct {
-	Success                       bool    `json:"success"`
-	CampaignID                    string  `json:"campaignId"`
-	Name                          string  `json:"name"`
-	Status                        string  `json:"status"`
-	CreatedAt                     string  `json:"createdAt"`
-	UpdatedAt                     string  `json:"updatedAt"`
-	EmailMessageID                string  `json:"emailMessageId"`
-	EmailMessageContentRevisionID *string `json:"emailMessageContentRevisionId"`
+	Success                       bool           `json:"success"`
+	CampaignID                    string         `json:"campaignId"`
+	Name                          string         `json:"name"`
+	Status                        CampaignStatus `json:"status"`
+	CreatedAt                     Timestamp      `json:"createdAt"`
+	UpdatedAt                     Timestamp      `json:"updatedAt"`
+	EmailMessageID                string         `json:"emailMessageId"`
+	EmailMessageContentRevisionID *string        `json:"emailMessageContentRevisionId"`
 }
 
 // UpdateCampaignRequest is the body for POST /campaigns/{campaignId}.
@@ -157,13 +157,13 @@ type UpdateCampaignRequest struct {
 
 // CampaignResponse is the 200 response for campaign reads and updates.
 type CampaignResponse struct {
-	Success        bool    `json:"success"`
-	CampaignID     string  `json:"campaignId"`
-	Name           string  `json:"name"`
-	Status         string  `json:"status"`
-	CreatedAt      string  `json:"createdAt"`
-	UpdatedAt      string  `json:"updatedAt"`
-	EmailMessageID *string `json:"emailMessageId"`
+	Success        bool           `json:"success"`
+	CampaignID     string         `json:"campaignId"`
+	Name           string         `json:"name"`
+	Status         CampaignStatus `json:"status"`
+	CreatedAt      Timestamp      `json:"createdAt"`
+	UpdatedAt      Timestamp      `json:"updatedAt"`
+	EmailMessageID *string        `json:"emailMessageId"`
 }
 
 // CampaignFailureResponse is used for campaign request failures.
@@ -185,17 +185,17 @@ type UpdateEmailMessageRequest struct {
 
 // EmailMessageResponse is the 200 response for email message reads and updates.
 type EmailMessageResponse struct {
-	Success           bool    `json:"success"`
-	EmailMessageID    string  `json:"emailMessageId"`
-	CampaignID        *string `json:"campaignId"`
-	Subject           string  `json:"subject"`
-	PreviewText       string  `json:"previewText"`
-	FromName          string  `json:"fromName"`
-	FromEmail         string  `json:"fromEmail"`
-	ReplyToEmail      string  `json:"replyToEmail"`
-	LMX               string  `json:"lmx"`
-	ContentRevisionID *string `json:"contentRevisionId"`
-	UpdatedAt         string  `json:"updatedAt"`
+	Success           bool      `json:"success"`
+	EmailMessageID    string    `json:"emailMessageId"`
+	CampaignID        *string   `json:"campaignId"`
+	Subject           string    `json:"subject"`
+	PreviewText       string    `json:"previewText"`
+	FromName          string    `json:"fromName"`
+	FromEmail         string    `json:"fromEmail"`
+	ReplyToEmail      string    `json:"replyToEmail"`
+	LMX               string    `json:"lmx"`
+	ContentRevisionID *string   `json:"contentRevisionId"`
+	UpdatedAt         Timestamp `json:"updatedAt"`
 }
 
 // EmailMessageFailureResponse is used for email message request failures.
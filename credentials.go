@@ -0,0 +1,128 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCredentialCacheTTL is how long a key from WithCredentialProvider is reused before the provider is asked again.
+const DefaultCredentialCacheTTL = 5 * time.Minute
+
+// WithCredentialProvider sources the API key from fn instead of the key passed to NewClient. The key is cached
+// for DefaultCredentialCacheTTL (see WithCredentialCacheTTL). When a request returns 401 the cached key is
+// dropped, fn is consulted again and, if it returns a different key, the request is retried once.
+func WithCredentialProvider(fn func(ctx context.Context) (string, error)) ClientOption {
+	return func(c *Client) {
+		ttl := DefaultCredentialCacheTTL
+		if c.creds != nil {
+			ttl = c.creds.ttl
+		}
+		c.creds = &credentialSource{fetch: fn, ttl: ttl}
+	}
+}
+
+// WithCredentialCacheTTL sets how long a provider-sourced key is cached. ttl <= 0 consults the provider on every
+// request. Has no effect without WithCredentialProvider.
+func WithCredentialCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		if c.creds == nil {
+			c.creds = &credentialSource{}
+		}
+		c.creds.ttl = ttl
+	}
+}
+
+// FileCredentialProvider returns a provider for WithCredentialProvider that reads the API key from path
+// (surrounding whitespace trimmed). The file is re-read only when its size or modification time changes.
+func FileCredentialProvider(path string) func(ctx context.Context) (string, error) {
+	var (
+		mu      sync.Mutex
+		key     string
+		modTime time.Time
+		size    int64 = -1
+	)
+	return func(context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fi, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		if fi.Size() == size && fi.ModTime().Equal(modTime) {
+			return key, nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		k := strings.TrimSpace(string(b))
+		if k == "" {
+			return "", fmt.Errorf("loops: credential file %s is empty", path)
+		}
+		key, modTime, size = k, fi.ModTime(), fi.Size()
+		return key, nil
+	}
+}
+
+// credentialSource caches the key returned by a provider func.
+type credentialSource struct {
+	fetch func(ctx context.Context) (string, error)
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	key       string
+	fetchedAt time.Time
+}
+
+func (s *credentialSource) get(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key != "" && s.ttl > 0 && time.Since(s.fetchedAt) < s.ttl {
+		return s.key, nil
+	}
+	key, err := s.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("loops: credential provider: %w", err)
+	}
+	s.key, s.fetchedAt = key, time.Now()
+	return key, nil
+}
+
+// invalidate drops the cached key if it is still stale (another request may already have refreshed it).
+func (s *credentialSource) invalidate(stale string) {
+	s.mu.Lock()
+	if s.key == stale {
+		s.key = ""
+	}
+	s.mu.Unlock()
+}
+
+// apiKeyFor returns the key to send: the provider's key when one is configured, otherwise the static key.
+func (c *Client) apiKeyFor(ctx context.Context) (string, error) {
+	if c.creds == nil || c.creds.fetch == nil {
+		return c.apiKey, nil
+	}
+	return c.creds.get(ctx)
+}
+
+// authorizedRoundTrip sends the request with key (from apiKeyFor) and, when a credential provider is configured,
+// refreshes the key and retries once on 401.
+func (c *Client) authorizedRoundTrip(ctx context.Context, method, path, key string, body []byte, opts *doOpts) ([]byte, error) {
+	slurp, err := c.roundTrip(ctx, method, path, key, body, opts)
+	var apiErr *APIError
+	if c.creds == nil || c.creds.fetch == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return slurp, err
+	}
+	c.creds.invalidate(key)
+	fresh, ferr := c.creds.get(ctx)
+	if ferr != nil || fresh == key {
+		return slurp, err
+	}
+	return c.roundTrip(ctx, method, path, fresh, body, opts)
+}
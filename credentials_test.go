@@ -0,0 +1,147 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CredentialProvider_CachesKey(t *testing.T) {
+	var auth atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"success":true,"teamName":"Acme"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var calls int32
+	client := NewClient("", WithBaseURL(server.URL), WithCredentialProvider(func(context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "rotating", nil
+	}))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetAPIKey(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := auth.Load(); got != "Bearer rotating" {
+		t.Errorf("Authorization: got %v", got)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("provider calls: got %d, want 1 (cached)", got)
+	}
+}
+
+func TestClient_CredentialProvider_RefreshesAndRetriesOn401(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success":false,"message":"Invalid API key"}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"id":"c1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	current := "old"
+	client := NewClient("", WithBaseURL(server.URL), WithCredentialProvider(func(context.Context) (string, error) {
+		return current, nil
+	}))
+	ctx := context.Background()
+	if _, err := client.GetAPIKey(ctx); err == nil {
+		t.Fatal("expected 401 with old key")
+	}
+
+	// Key rotated: the next 401 triggers a refresh and a single retry with the body intact.
+	current = "new"
+	got, err := client.CreateContact(ctx, &ContactRequest{Email: "u@example.com"})
+	if err != nil {
+		t.Fatalf("expected retry with new key to succeed: %v", err)
+	}
+	if got.ID != "c1" {
+		t.Errorf("got %+v", got)
+	}
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("server hits: got %d, want 3", n)
+	}
+
+	// Unchanged key: no pointless retry.
+	current = "revoked"
+	client.creds.invalidate("new")
+	atomic.StoreInt32(&hits, 0)
+	_, err = client.GetAPIKey(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("server hits: got %d, want 1", n)
+	}
+}
+
+func TestClient_CredentialProvider_Error(t *testing.T) {
+	client := NewClient("", WithCredentialProvider(func(context.Context) (string, error) {
+		return "", errors.New("vault sealed")
+	}))
+	_, err := client.GetAPIKey(context.Background())
+	if err == nil || err.Error() != "loops: credential provider: vault sealed" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestClient_CredentialProvider_ErrorDoesNotTripBreaker(t *testing.T) {
+	var transitions []string
+	client := NewClient("", WithCredentialProvider(func(context.Context) (string, error) {
+		return "", errors.New("vault sealed")
+	}), WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Hour,
+		OnStateChange: func(group string, _, to CircuitState) {
+			transitions = append(transitions, group+":"+to.String())
+		},
+	}))
+	for i := 0; i < 4; i++ {
+		_, err := client.GetLists(context.Background())
+		if errors.Is(err, ErrCircuitOpen) || err == nil || err.Error() != "loops: credential provider: vault sealed" {
+			t.Fatalf("call %d: got %v", i, err)
+		}
+	}
+	if len(transitions) != 0 {
+		t.Errorf("breaker transitions: %v", transitions)
+	}
+}
+
+func TestFileCredentialProvider_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loops-key")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	provider := FileCredentialProvider(path)
+	ctx := context.Background()
+	if k, err := provider(ctx); err != nil || k != "first" {
+		t.Fatalf("got %q, %v", k, err)
+	}
+	if err := os.WriteFile(path, []byte("second-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	os.Chtimes(path, later, later)
+	if k, err := provider(ctx); err != nil || k != "second-key" {
+		t.Fatalf("got %q, %v", k, err)
+	}
+	if err := os.WriteFile(path, []byte("  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider(ctx); err == nil {
+		t.Error("expected error for empty credential file")
+	}
+}
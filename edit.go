@@ -0,0 +1,98 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultEditAttempts is how many times EditEmailMessage tries to apply a mutation before giving up.
+const DefaultEditAttempts = 3
+
+// EditConflictError is returned by EditEmailMessage when the message kept changing underneath the edit
+// (409 with a new contentRevisionId) on every attempt. Err is the last 409 *APIError.
+type EditConflictError struct {
+	EmailMessageID string
+	Attempts       int
+	Err            error
+}
+
+func (e *EditConflictError) Error() string {
+	return fmt.Sprintf("loops: email message %s: revision conflict after %d attempts: %v", e.EmailMessageID, e.Attempts, e.Err)
+}
+
+func (e *EditConflictError) Unwrap() error { return e.Err }
+
+// EditOption configures EditEmailMessage.
+type EditOption func(*editConfig)
+
+type editConfig struct {
+	attempts int
+}
+
+// WithEditAttempts sets the maximum number of fetch-mutate-update attempts (default DefaultEditAttempts).
+func WithEditAttempts(n int) EditOption {
+	return func(c *editConfig) { c.attempts = n }
+}
+
+// EditEmailMessage applies mutate to an email message with optimistic concurrency. It fetches the message, calls
+// mutate with it and sends the returned request with ExpectedRevisionID set to the fetched contentRevisionId.
+// If the update is rejected with 409 because the revision moved on, it refetches and calls mutate again, up to the
+// configured number of attempts, then returns *EditConflictError. A 409 for any other reason (the campaign is not a
+// draft, unparseable content) is returned as is. If mutate returns a nil request, nothing is sent and the fetched
+// message is returned. mutate must not keep the message it is given between calls.
+func (c *Client) EditEmailMessage(ctx context.Context, emailMessageID string, mutate func(*EmailMessageResponse) (*UpdateEmailMessageRequest, error), opts ...EditOption) (*EmailMessageResponse, error) {
+	if mutate == nil {
+		return nil, &APIError{StatusCode: 400, Message: "mutate is required"}
+	}
+	cfg := editConfig{attempts: DefaultEditAttempts}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.attempts < 1 {
+		cfg.attempts = 1
+	}
+
+	current, err := c.GetEmailMessage(ctx, emailMessageID)
+	if err != nil {
+		return nil, err
+	}
+	var lastConflict error
+	for attempt := 1; attempt <= cfg.attempts; attempt++ {
+		req, err := mutate(current)
+		if err != nil {
+			return nil, err
+		}
+		if req == nil {
+			return current, nil
+		}
+		update := *req
+		update.ExpectedRevisionID = ""
+		if current.ContentRevisionID != nil {
+			update.ExpectedRevisionID = *current.ContentRevisionID
+		}
+		out, err := c.UpdateEmailMessage(ctx, emailMessageID, &update)
+		var apiErr *APIError
+		if err == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+			return out, err
+		}
+		// 409 is also used for non-draft campaigns and unparseable content; only a moved revision is worth retrying.
+		latest, ferr := c.GetEmailMessage(ctx, emailMessageID)
+		if ferr != nil {
+			return nil, ferr
+		}
+		if sameRevision(latest.ContentRevisionID, current.ContentRevisionID) {
+			return nil, err
+		}
+		lastConflict, current = err, latest
+	}
+	return nil, &EditConflictError{EmailMessageID: emailMessageID, Attempts: cfg.attempts, Err: lastConflict}
+}
+
+func sameRevision(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
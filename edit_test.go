@@ -0,0 +1,108 @@
+package loops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// editServer serves one email message. Each GET returns the current revision; if bumpOnGet is set, a concurrent
+// writer advances the revision right after every read, so updates always conflict.
+func editServer(t *testing.T, bumpOnGet int, draft bool) (*Client, func() (int, string)) {
+	t.Helper()
+	var mu sync.Mutex
+	rev, subject, updates := 1, "Old", 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `{"success":true,"emailMessageId":"em_1","subject":%q,"contentRevisionId":"rev_%d"}`, subject, rev)
+			if bumpOnGet > 0 {
+				bumpOnGet--
+				rev++
+			}
+			return
+		}
+		var req UpdateEmailMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !draft {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"success":false,"message":"Campaign is not in draft status."}`))
+			return
+		}
+		if req.ExpectedRevisionID != fmt.Sprintf("rev_%d", rev) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"success":false,"message":"Stale contentRevisionId."}`))
+			return
+		}
+		updates++
+		rev++
+		subject = req.Subject
+		fmt.Fprintf(w, `{"success":true,"emailMessageId":"em_1","subject":%q,"contentRevisionId":"rev_%d"}`, subject, rev)
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("key", WithBaseURL(server.URL)), func() (int, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return updates, subject
+	}
+}
+
+func appendSubject(m *EmailMessageResponse) (*UpdateEmailMessageRequest, error) {
+	return &UpdateEmailMessageRequest{Subject: m.Subject + "!"}, nil
+}
+
+func TestClient_EditEmailMessage_RetriesOnConflict(t *testing.T) {
+	client, state := editServer(t, 2, true)
+	calls := 0
+	out, err := client.EditEmailMessage(context.Background(), "em_1", func(m *EmailMessageResponse) (*UpdateEmailMessageRequest, error) {
+		calls++
+		return appendSubject(m)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updates, subject := state(); updates != 1 || subject != "Old!" || out.Subject != "Old!" || calls != 3 {
+		t.Errorf("updates=%d subject=%q calls=%d", updates, subject, calls)
+	}
+}
+
+func TestClient_EditEmailMessage_GivesUp(t *testing.T) {
+	client, state := editServer(t, 100, true)
+	_, err := client.EditEmailMessage(context.Background(), "em_1", appendSubject, WithEditAttempts(2))
+	var conflict *EditConflictError
+	if !errors.As(err, &conflict) || conflict.Attempts != 2 {
+		t.Fatalf("expected EditConflictError, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("EditConflictError should wrap the 409: %v", err)
+	}
+	if updates, _ := state(); updates != 0 {
+		t.Errorf("updates: %d", updates)
+	}
+}
+
+func TestClient_EditEmailMessage_NonRevisionConflictAndNoop(t *testing.T) {
+	client, _ := editServer(t, 0, false)
+	calls := 0
+	_, err := client.EditEmailMessage(context.Background(), "em_1", func(m *EmailMessageResponse) (*UpdateEmailMessageRequest, error) {
+		calls++
+		return appendSubject(m)
+	})
+	var conflict *EditConflictError
+	if err == nil || errors.As(err, &conflict) || !strings.Contains(err.Error(), "not in draft") || calls != 1 {
+		t.Errorf("expected the plain 409 after one attempt, got %v (calls %d)", err, calls)
+	}
+
+	out, err := client.EditEmailMessage(context.Background(), "em_1", func(*EmailMessageResponse) (*UpdateEmailMessageRequest, error) { return nil, nil })
+	if err != nil || out.Subject != "Old" {
+		t.Errorf("no-op edit: %+v, %v", out, err)
+	}
+}
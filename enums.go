@@ -0,0 +1,55 @@
+package loops
+
+// CampaignStatus is a campaign's lifecycle status. Values decode exactly as received, including ones the SDK
+// does not know (added to the API later); IsValid reports whether the value is one of the constants below.
+type CampaignStatus string
+
+const (
+	CampaignDraft     CampaignStatus = "Draft"
+	CampaignScheduled CampaignStatus = "Scheduled"
+	CampaignSending   CampaignStatus = "Sending"
+	CampaignSent      CampaignStatus = "Sent"
+)
+
+var campaignStatuses = []string{"Draft", "Scheduled", "Sending", "Sent"}
+
+// IsValid reports whether s is a known campaign status.
+func (s CampaignStatus) IsValid() bool { return isEnum(string(s), campaignStatuses) }
+
+// OptInStatus is a contact's double opt-in status (OpenAPI Contact.optInStatus).
+type OptInStatus string
+
+const (
+	OptInAccepted OptInStatus = "accepted"
+	OptInPending  OptInStatus = "pending"
+	OptInRejected OptInStatus = "rejected"
+)
+
+var optInStatuses = []string{"accepted", "pending", "rejected"}
+
+// IsValid reports whether s is a known opt-in status.
+func (s OptInStatus) IsValid() bool { return isEnum(string(s), optInStatuses) }
+
+// PropertyType is the type of a contact property.
+type PropertyType string
+
+const (
+	PropertyString  PropertyType = "string"
+	PropertyNumber  PropertyType = "number"
+	PropertyBoolean PropertyType = "boolean"
+	PropertyDate    PropertyType = "date"
+)
+
+var propertyTypes = []string{"string", "number", "boolean", "date"}
+
+// IsValid reports whether t is a known property type.
+func (t PropertyType) IsValid() bool { return isEnum(string(t), propertyTypes) }
+
+func isEnum(s string, known []string) bool {
+	for _, k := range known {
+		if s == k {
+			return true
+		}
+	}
+	return false
+}
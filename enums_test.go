@@ -0,0 +1,58 @@
+package loops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnums_JSON(t *testing.T) {
+	var c CampaignListItem
+	if err := json.Unmarshal([]byte(`{"status":"Draft"}`), &c); err != nil || c.Status != CampaignDraft || !c.Status.IsValid() {
+		t.Errorf("known value: %q, %v", c.Status, err)
+	}
+	// Values are kept verbatim, so they encode back as received.
+	if err := json.Unmarshal([]byte(`{"status":"SENT"}`), &c); err != nil || c.Status != "SENT" || c.Status.IsValid() {
+		t.Errorf("differently cased value: %q, %v", c.Status, err)
+	}
+	if b, err := json.Marshal(c); err != nil || !strings.Contains(string(b), `"status":"SENT"`) {
+		t.Errorf("marshal: %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"status":"Archived"}`), &c); err != nil || c.Status != "Archived" || c.Status.IsValid() {
+		t.Errorf("unknown value: %q, %v", c.Status, err)
+	}
+	if b, err := json.Marshal(c); err != nil || !strings.Contains(string(b), `"status":"Archived"`) {
+		t.Errorf("marshal: %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"status":3}`), &c); err == nil {
+		t.Error("number accepted as a status")
+	}
+
+	var contact Contact
+	if err := json.Unmarshal([]byte(`{"optInStatus":"pending"}`), &contact); err != nil || contact.OptInStatus == nil || *contact.OptInStatus != OptInPending {
+		t.Errorf("opt-in: %v, %v", contact.OptInStatus, err)
+	}
+	contact = Contact{}
+	if err := json.Unmarshal([]byte(`{"optInStatus":null}`), &contact); err != nil || contact.OptInStatus != nil {
+		t.Errorf("null opt-in: %v, %v", contact.OptInStatus, err)
+	}
+
+	var props []ContactProperty
+	if err := json.Unmarshal([]byte(`[{"key":"plan","type":"string"},{"key":"geo","type":"location"}]`), &props); err != nil {
+		t.Fatal(err)
+	}
+	if props[0].Type != PropertyString || props[1].Type != "location" || props[1].Type.IsValid() {
+		t.Errorf("property types: %+v", props)
+	}
+}
+
+func TestClient_CreateContactProperty_InvalidType_ReturnsErrorBeforeRequest(t *testing.T) {
+	client := NewClient("key", WithBaseURL("http://127.0.0.1:0"))
+	_, err := client.CreateContactProperty(context.Background(), &ContactPropertyCreateRequest{Name: "plan", Type: "text"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package loops
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrErasureNotVerified is returned by EraseContact when the contact can still be found after deletion.
+var ErrErasureNotVerified = errors.New("loops: contact still present after deletion")
+
+// ErrReceiptSignature is returned by ErasureReceipt.Verify when the signature does not match.
+var ErrReceiptSignature = errors.New("loops: erasure receipt signature mismatch")
+
+// ReceiptSigner signs erasure receipts. Implementations may wrap a KMS or HSM.
+type ReceiptSigner interface {
+	// Algorithm names the signature scheme, recorded in the receipt (e.g. "HMAC-SHA256").
+	Algorithm() string
+	Sign(payload []byte) ([]byte, error)
+}
+
+// ReceiptVerifier checks receipt signatures produced by the matching ReceiptSigner.
+type ReceiptVerifier interface {
+	Algorithm() string
+	Verify(payload, signature []byte) error
+}
+
+// HMACReceiptSigner signs and verifies receipts with HMAC-SHA256.
+type HMACReceiptSigner struct {
+	Key []byte
+}
+
+// Algorithm returns "HMAC-SHA256".
+func (s HMACReceiptSigner) Algorithm() string { return "HMAC-SHA256" }
+
+// Sign returns the HMAC-SHA256 of payload.
+func (s HMACReceiptSigner) Sign(payload []byte) ([]byte, error) {
+	if len(s.Key) == 0 {
+		return nil, errors.New("loops: HMAC receipt key is empty")
+	}
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write(payload)
+	return mac.Sum(nil), nil
+}
+
+// Verify checks an HMAC-SHA256 signature in constant time.
+func (s HMACReceiptSigner) Verify(payload, signature []byte) error {
+	want, err := s.Sign(payload)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(want, signature) {
+		return ErrReceiptSignature
+	}
+	return nil
+}
+
+// Ed25519ReceiptSigner signs receipts with PrivateKey and verifies them with PublicKey (derived from PrivateKey
+// when unset), so receipts can be verified by parties that do not hold the signing key.
+type Ed25519ReceiptSigner struct {
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// Algorithm returns "Ed25519".
+func (s Ed25519ReceiptSigner) Algorithm() string { return "Ed25519" }
+
+// Sign returns the Ed25519 signature of payload.
+func (s Ed25519ReceiptSigner) Sign(payload []byte) ([]byte, error) {
+	if len(s.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("loops: invalid Ed25519 private key")
+	}
+	return ed25519.Sign(s.PrivateKey, payload), nil
+}
+
+// Verify checks an Ed25519 signature.
+func (s Ed25519ReceiptSigner) Verify(payload, signature []byte) error {
+	pub := s.PublicKey
+	if pub == nil && len(s.PrivateKey) == ed25519.PrivateKeySize {
+		pub = s.PrivateKey.Public().(ed25519.PublicKey)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return errors.New("loops: invalid Ed25519 public key")
+	}
+	if !ed25519.Verify(pub, payload, signature) {
+		return ErrReceiptSignature
+	}
+	return nil
+}
+
+// ErasureOptions configures EraseContact.
+type ErasureOptions struct {
+	// Signer signs the receipt. Without one the receipt is produced unsigned.
+	Signer ReceiptSigner
+	// RequestID is an external reference (e.g. the ticket number of the erasure request) stored in the receipt.
+	RequestID string
+}
+
+// Erasure outcomes recorded in ErasureReceipt.Outcome.
+const (
+	ErasureErased   = "erased"
+	ErasureNotFound = "not_found"
+	ErasureFailed   = "failed"
+)
+
+// Erasure step statuses recorded in ErasureStep.Status.
+const (
+	StepOK      = "ok"
+	StepFailed  = "failed"
+	StepSkipped = "skipped"
+)
+
+// ErasureStep is one step of the erasure workflow.
+type ErasureStep struct {
+	Name        string    `json:"name"` // "lookup", "suppression", "delete" or "verify"
+	Status      string    `json:"status"`
+	Detail      string    `json:"detail,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// ErasureReceipt is the audit record of an EraseContact call. The erased identifier is stored only as a SHA-256
+// hash (SubjectHash) so the receipt itself holds no personal data; recompute it with ErasureSubjectHash to match a
+// receipt to a request.
+type ErasureReceipt struct {
+	Version      int           `json:"version"`
+	RequestID    string        `json:"requestId,omitempty"`
+	SubjectType  string        `json:"subjectType"` // "email" or "userId"
+	SubjectHash  string        `json:"subjectHash"`
+	ContactID    string        `json:"contactId,omitempty"`
+	Outcome      string        `json:"outcome"`
+	IsSuppressed *bool         `json:"isSuppressed,omitempty"` // suppression status before deletion, when known
+	StartedAt    time.Time     `json:"startedAt"`
+	CompletedAt  time.Time     `json:"completedAt"`
+	Steps        []ErasureStep `json:"steps"`
+	Algorithm    string        `json:"algorithm,omitempty"`
+	Signature    string        `json:"signature,omitempty"` // base64 signature over the receipt without this field
+}
+
+// ErasureSubjectHash returns the hex SHA-256 of an identifier as stored in ErasureReceipt.SubjectHash. Emails are
+// trimmed and lower-cased first.
+func ErasureSubjectHash(subjectType, identifier string) string {
+	identifier = strings.TrimSpace(identifier)
+	if subjectType == "email" {
+		identifier = strings.ToLower(identifier)
+	}
+	sum := sha256.Sum256([]byte(subjectType + ":" + identifier))
+	return hex.EncodeToString(sum[:])
+}
+
+// signingPayload is the receipt JSON with the signature cleared.
+func (r *ErasureReceipt) signingPayload() ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Verify checks the receipt's signature with v.
+func (r *ErasureReceipt) Verify(v ReceiptVerifier) error {
+	if r.Signature == "" {
+		return errors.New("loops: erasure receipt is not signed")
+	}
+	if r.Algorithm != v.Algorithm() {
+		return fmt.Errorf("loops: erasure receipt signed with %s, verifier is %s", r.Algorithm, v.Algorithm())
+	}
+	sig, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return fmt.Errorf("loops: decode erasure receipt signature: %w", err)
+	}
+	payload, err := r.signingPayload()
+	if err != nil {
+		return err
+	}
+	return v.Verify(payload, sig)
+}
+
+// EraseContact runs a right-to-be-forgotten workflow for the contact identified by exactly one of email or userID:
+// it looks the contact up, records its suppression status, deletes it and verifies it can no longer be found.
+// It always returns a receipt (signed when opts.Signer is set) describing each step, including when the contact
+// did not exist (Outcome "not_found") or a step failed (Outcome "failed", with the error also returned).
+func (c *Client) EraseContact(ctx context.Context, email, userID string, opts ErasureOptions) (*ErasureReceipt, error) {
+	if (email == "") == (userID == "") {
+		return nil, &APIError{StatusCode: 400, Message: "exactly one of email or userId is required"}
+	}
+	subjectType, identifier := "email", email
+	if userID != "" {
+		subjectType, identifier = "userId", userID
+	}
+	r := &ErasureReceipt{
+		Version:     1,
+		RequestID:   opts.RequestID,
+		SubjectType: subjectType,
+		SubjectHash: ErasureSubjectHash(subjectType, identifier),
+		StartedAt:   time.Now().UTC(),
+	}
+	step := func(name string, fn func() (status, detail string, err error)) error {
+		s := ErasureStep{Name: name, StartedAt: time.Now().UTC()}
+		status, detail, err := fn()
+		s.Status, s.Detail, s.CompletedAt = status, detail, time.Now().UTC()
+		if err != nil && detail == "" {
+			s.Detail = err.Error()
+		}
+		r.Steps = append(r.Steps, s)
+		return err
+	}
+
+	err := c.eraseContact(ctx, email, userID, r, step)
+	switch {
+	case err != nil:
+		r.Outcome = ErasureFailed
+	case r.ContactID == "":
+		r.Outcome = ErasureNotFound
+	default:
+		r.Outcome = ErasureErased
+	}
+	r.CompletedAt = time.Now().UTC()
+	if opts.Signer != nil {
+		r.Algorithm = opts.Signer.Algorithm()
+		payload, perr := r.signingPayload()
+		if perr == nil {
+			var sig []byte
+			sig, perr = opts.Signer.Sign(payload)
+			r.Signature = base64.StdEncoding.EncodeToString(sig)
+		}
+		if perr != nil {
+			r.Algorithm, r.Signature = "", ""
+			if err == nil {
+				err = fmt.Errorf("loops: sign erasure receipt: %w", perr)
+			}
+		}
+	}
+	return r, err
+}
+
+func (c *Client) eraseContact(ctx context.Context, email, userID string, r *ErasureReceipt, step func(string, func() (string, string, error)) error) error {
+	skipRest := func(names ...string) {
+		for _, n := range names {
+			now := time.Now().UTC()
+			r.Steps = append(r.Steps, ErasureStep{Name: n, Status: StepSkipped, StartedAt: now, CompletedAt: now})
+		}
+	}
+	err := step("lookup", func() (string, string, error) {
+		found, err := c.FindContact(ctx, email, userID)
+		if err != nil {
+			return StepFailed, "", err
+		}
+		if len(found) == 0 {
+			return StepOK, "contact not found", nil
+		}
+		r.ContactID = found[0].ID
+		return StepOK, "found contact " + r.ContactID, nil
+	})
+	if err != nil {
+		skipRest("suppression", "delete", "verify")
+		return err
+	}
+	if r.ContactID == "" {
+		skipRest("suppression", "delete", "verify")
+		return nil
+	}
+
+	// Suppression status is informational; a failure here does not block the deletion.
+	_ = step("suppression", func() (string, string, error) {
+		status, err := c.GetContactSuppression(ctx, email, userID)
+		if err != nil {
+			return StepFailed, "", err
+		}
+		suppressed := status.IsSuppressed
+		r.IsSuppressed = &suppressed
+		return StepOK, fmt.Sprintf("isSuppressed=%t", suppressed), nil
+	})
+
+	err = step("delete", func() (string, string, error) {
+		resp, err := c.DeleteContact(ctx, &ContactDeleteRequest{Email: email, UserID: userID})
+		if err != nil {
+			return StepFailed, "", err
+		}
+		return StepOK, resp.Message, nil
+	})
+	if err != nil {
+		skipRest("verify")
+		return err
+	}
+
+	return step("verify", func() (string, string, error) {
+		found, err := c.FindContact(ctx, email, userID)
+		if err != nil {
+			return StepFailed, "", err
+		}
+		if len(found) > 0 {
+			return StepFailed, "", ErrErasureNotVerified
+		}
+		return StepOK, "contact no longer found", nil
+	})
+}
@@ -0,0 +1,113 @@
+package loops
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// erasureServer holds one contact; deleteWorks controls whether DeleteContact actually removes it.
+func erasureServer(t *testing.T, exists, deleteWorks bool) *Client {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/contacts/find":
+			if exists {
+				w.Write([]byte(`[{"id":"c1","email":"u@example.com","subscribed":true}]`))
+			} else {
+				w.Write([]byte(`[]`))
+			}
+		case "/contacts/suppression":
+			w.Write([]byte(`{"contact":{"id":"c1","email":"u@example.com","userId":null},"isSuppressed":true,"removalQuota":{"limit":10,"remaining":9}}`))
+		case "/contacts/delete":
+			if deleteWorks {
+				exists = false
+			}
+			w.Write([]byte(`{"success":true,"message":"Contact deleted."}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("key", WithBaseURL(server.URL))
+}
+
+func TestClient_EraseContact_SignedReceipt(t *testing.T) {
+	client := erasureServer(t, true, true)
+	signer := HMACReceiptSigner{Key: []byte("audit-secret")}
+	r, err := client.EraseContact(context.Background(), "U@Example.com ", "", ErasureOptions{Signer: signer, RequestID: "DSR-42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Outcome != ErasureErased || r.ContactID != "c1" || r.RequestID != "DSR-42" {
+		t.Errorf("receipt: %+v", r)
+	}
+	if r.IsSuppressed == nil || !*r.IsSuppressed {
+		t.Errorf("IsSuppressed: %v", r.IsSuppressed)
+	}
+	if r.SubjectHash != ErasureSubjectHash("email", "u@example.com") {
+		t.Error("subject hash should normalise the email")
+	}
+	var names []string
+	for _, s := range r.Steps {
+		if s.Status != StepOK {
+			t.Errorf("step %s: %s %s", s.Name, s.Status, s.Detail)
+		}
+		names = append(names, s.Name)
+	}
+	if len(names) != 4 || names[0] != "lookup" || names[3] != "verify" {
+		t.Errorf("steps: %v", names)
+	}
+
+	// Round-trip through JSON (as stored) and verify; tampering breaks the signature.
+	b, _ := json.Marshal(r)
+	var stored ErasureReceipt
+	if err := json.Unmarshal(b, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if err := stored.Verify(signer); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	stored.ContactID = "c2"
+	if err := stored.Verify(signer); !errors.Is(err, ErrReceiptSignature) {
+		t.Errorf("expected signature mismatch after tampering, got %v", err)
+	}
+}
+
+func TestClient_EraseContact_Ed25519AndNotFound(t *testing.T) {
+	client := erasureServer(t, false, true)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	r, err := client.EraseContact(context.Background(), "", "user_1", ErasureOptions{Signer: Ed25519ReceiptSigner{PrivateKey: priv}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Outcome != ErasureNotFound || r.SubjectType != "userId" || r.Algorithm != "Ed25519" {
+		t.Errorf("receipt: %+v", r)
+	}
+	if len(r.Steps) != 4 || r.Steps[2].Status != StepSkipped {
+		t.Errorf("steps: %+v", r.Steps)
+	}
+	if err := r.Verify(Ed25519ReceiptSigner{PublicKey: pub}); err != nil {
+		t.Errorf("verify with public key only: %v", err)
+	}
+}
+
+func TestClient_EraseContact_VerificationFails(t *testing.T) {
+	client := erasureServer(t, true, false)
+	r, err := client.EraseContact(context.Background(), "u@example.com", "", ErasureOptions{})
+	if !errors.Is(err, ErrErasureNotVerified) {
+		t.Fatalf("expected ErrErasureNotVerified, got %v", err)
+	}
+	if r == nil || r.Outcome != ErasureFailed || r.Signature != "" {
+		t.Errorf("receipt: %+v", r)
+	}
+	if _, err := client.EraseContact(context.Background(), "a@b.com", "u1", ErasureOptions{}); err == nil {
+		t.Error("expected error when both identifiers are given")
+	}
+}
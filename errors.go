@@ -2,10 +2,15 @@ package loops
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
 
+// ErrCircuitOpen is returned (wrapped with the endpoint group) when a request is rejected by an open
+// circuit breaker without being sent. Check with errors.Is.
+var ErrCircuitOpen = errors.New("loops: circuit breaker open")
+
 // APIError represents an error response from the Loops API (success: false with message).
 // It implements error and preserves the HTTP status code and raw body when available.
 type APIError struct {
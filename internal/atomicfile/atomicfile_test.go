@@ -0,0 +1,28 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	for _, data := range []string{"first", "second"} {
+		if err := WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := os.ReadFile(path); string(b) != data {
+			t.Errorf("got %q, want %q", b, data)
+		}
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0o644 {
+		t.Errorf("mode %v", fi.Mode().Perm())
+	}
+	if tmp, _ := filepath.Glob(path + ".tmp*"); len(tmp) > 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+	if err := WriteFile(filepath.Join(path, "missing", "x"), nil, 0o644); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
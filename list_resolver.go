@@ -0,0 +1,149 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownList means no mailing list has the given name.
+	ErrUnknownList = errors.New("unknown mailing list")
+	// ErrAmbiguousList means more than one mailing list matches the given name.
+	ErrAmbiguousList = errors.New("ambiguous mailing list name")
+	// ErrListNotPublic means the list exists but is not public and the resolver only allows public lists.
+	ErrListNotPublic = errors.New("mailing list is not public")
+)
+
+// ListNameError reports a mailing list name that could not be resolved. It unwraps to ErrUnknownList,
+// ErrAmbiguousList or ErrListNotPublic.
+type ListNameError struct {
+	Name string
+	IDs  []string // matching list IDs (ambiguous or non-public matches)
+	Err  error
+}
+
+func (e *ListNameError) Error() string {
+	if len(e.IDs) > 0 {
+		return fmt.Sprintf("loops: %v %q (%s)", e.Err, e.Name, strings.Join(e.IDs, ", "))
+	}
+	return fmt.Sprintf("loops: %v %q", e.Err, e.Name)
+}
+
+func (e *ListNameError) Unwrap() error { return e.Err }
+
+// ListResolver resolves mailing list names to IDs with GetLists, so the same code works across teams
+// (e.g. staging and production) whose list IDs differ. Lists are fetched on every call; combine with
+// WithCache to avoid the round trip.
+type ListResolver struct {
+	client     *Client
+	publicOnly bool
+}
+
+// ListResolverOption configures a ListResolver.
+type ListResolverOption func(*ListResolver)
+
+// PublicListsOnly makes the resolver reject lists whose IsPublic is false, for user-facing flows.
+func PublicListsOnly() ListResolverOption {
+	return func(r *ListResolver) {
+		r.publicOnly = true
+	}
+}
+
+// NewListResolver returns a resolver that looks up lists through client.
+func NewListResolver(client *Client, opts ...ListResolverOption) *ListResolver {
+	r := &ListResolver{client: client}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve converts a {list name: subscribed} map into the {list ID: subscribed} map expected by
+// ContactRequest.MailingLists, ContactUpdateRequest.MailingLists and EventRequest.MailingLists.
+// Names match exactly, falling back to a case-insensitive match. It fails with a *ListNameError on the
+// first (alphabetical) name that is unknown, ambiguous or, with PublicListsOnly, not public.
+func (r *ListResolver) Resolve(ctx context.Context, byName map[string]bool) (map[string]bool, error) {
+	if len(byName) == 0 {
+		return nil, nil
+	}
+	lists, err := r.client.GetLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make(map[string]bool, len(byName))
+	for _, name := range names {
+		id, err := r.match(lists, name)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = byName[name]
+	}
+	return out, nil
+}
+
+// ListID resolves a single list name to its ID.
+func (r *ListResolver) ListID(ctx context.Context, name string) (string, error) {
+	lists, err := r.client.GetLists(ctx)
+	if err != nil {
+		return "", err
+	}
+	return r.match(lists, name)
+}
+
+// Subscribe is a convenience for Resolve with every named list set to true.
+func (r *ListResolver) Subscribe(ctx context.Context, names ...string) (map[string]bool, error) {
+	return r.Resolve(ctx, namesTo(names, true))
+}
+
+// Unsubscribe is a convenience for Resolve with every named list set to false.
+func (r *ListResolver) Unsubscribe(ctx context.Context, names ...string) (map[string]bool, error) {
+	return r.Resolve(ctx, namesTo(names, false))
+}
+
+func namesTo(names []string, v bool) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = v
+	}
+	return m
+}
+
+func (r *ListResolver) match(lists []MailingList, name string) (string, error) {
+	var matches []MailingList
+	for _, l := range lists {
+		if l.Name == name {
+			matches = append(matches, l)
+		}
+	}
+	if len(matches) == 0 {
+		want := strings.TrimSpace(name)
+		for _, l := range lists {
+			if strings.EqualFold(strings.TrimSpace(l.Name), want) {
+				matches = append(matches, l)
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", &ListNameError{Name: name, Err: ErrUnknownList}
+	case 1:
+	default:
+		ids := make([]string, len(matches))
+		for i, l := range matches {
+			ids[i] = l.ID
+		}
+		return "", &ListNameError{Name: name, IDs: ids, Err: ErrAmbiguousList}
+	}
+	if r.publicOnly && !matches[0].IsPublic {
+		return "", &ListNameError{Name: name, IDs: []string{matches[0].ID}, Err: ErrListNotPublic}
+	}
+	return matches[0].ID, nil
+}
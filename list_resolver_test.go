@@ -0,0 +1,71 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func listResolverServer(t *testing.T) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`[
+			{"id":"l_news","name":"Newsletter","description":"","isPublic":true},
+			{"id":"l_beta","name":"Beta testers","description":"","isPublic":false},
+			{"id":"l_dup1","name":"Updates","description":"","isPublic":true},
+			{"id":"l_dup2","name":"updates","description":"","isPublic":true}
+		]`))
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("key", WithBaseURL(server.URL))
+}
+
+func TestListResolver_Resolve(t *testing.T) {
+	r := NewListResolver(listResolverServer(t))
+	ctx := context.Background()
+	got, err := r.Resolve(ctx, map[string]bool{"Newsletter": true, "beta TESTERS": false, "Updates": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"l_news": true, "l_beta": false, "l_dup1": true}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for id, v := range want {
+		if got[id] != v {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	id, err := r.ListID(ctx, "newsletter")
+	if err != nil || id != "l_news" {
+		t.Errorf("ListID: %q, %v", id, err)
+	}
+	sub, err := r.Unsubscribe(ctx, "Newsletter")
+	if err != nil || len(sub) != 1 || sub["l_news"] != false {
+		t.Errorf("Unsubscribe: %v, %v", sub, err)
+	}
+}
+
+func TestListResolver_Errors(t *testing.T) {
+	client := listResolverServer(t)
+	ctx := context.Background()
+
+	_, err := NewListResolver(client).Subscribe(ctx, "Nope")
+	if !errors.Is(err, ErrUnknownList) {
+		t.Errorf("expected ErrUnknownList, got %v", err)
+	}
+
+	_, err = NewListResolver(client).Subscribe(ctx, "UPDATES")
+	var nameErr *ListNameError
+	if !errors.As(err, &nameErr) || !errors.Is(err, ErrAmbiguousList) || len(nameErr.IDs) != 2 {
+		t.Errorf("expected ambiguous ListNameError with 2 IDs, got %v", err)
+	}
+
+	_, err = NewListResolver(client, PublicListsOnly()).Subscribe(ctx, "Beta testers")
+	if !errors.Is(err, ErrListNotPublic) {
+		t.Errorf("expected ErrListNotPublic, got %v", err)
+	}
+}
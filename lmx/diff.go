@@ -0,0 +1,273 @@
+package lmx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// FieldChange is a changed campaign or email message field, by its API name.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Change is an added, removed or changed block. Path locates it XPath-style by tag name and 1-based position
+// among same-named siblings, such as "/Section[2]/Text[1]", in the new document (the old one for removals).
+// Old and New are the block rendered as LMX.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	Path string     `json:"path"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// MessageDiff is the difference between two email messages. It marshals to JSON as {"fields": [...], "blocks": [...]}.
+type MessageDiff struct {
+	Fields []FieldChange `json:"fields"`
+	Blocks []Change      `json:"blocks"`
+}
+
+// Empty reports whether the messages are equivalent.
+func (d *MessageDiff) Empty() bool { return len(d.Fields) == 0 && len(d.Blocks) == 0 }
+
+// DiffMessages compares the header fields (subject, previewText, fromName, fromEmail, replyToEmail) and the LMX
+// bodies of two email messages, for example two revisions fetched with GetEmailMessage. Bodies are compared as
+// block trees, so reformatting is not a change. A body that does not parse returns its *SyntaxError.
+func DiffMessages(old, new *loops.EmailMessageResponse) (*MessageDiff, error) {
+	d := &MessageDiff{Fields: []FieldChange{}, Blocks: []Change{}}
+	for _, f := range []FieldChange{
+		{"subject", old.Subject, new.Subject},
+		{"previewText", old.PreviewText, new.PreviewText},
+		{"fromName", old.FromName, new.FromName},
+		{"fromEmail", old.FromEmail, new.FromEmail},
+		{"replyToEmail", old.ReplyToEmail, new.ReplyToEmail},
+	} {
+		if f.Old != f.New {
+			d.Fields = append(d.Fields, f)
+		}
+	}
+	a, err := Parse(old.LMX)
+	if err != nil {
+		return nil, err
+	}
+	b, err := Parse(new.LMX)
+	if err != nil {
+		return nil, err
+	}
+	d.Blocks = append(d.Blocks, Diff(a, b)...)
+	return d, nil
+}
+
+// Diff compares two documents block by block. Sibling blocks are aligned on their rendered LMX; a replaced
+// block with the same tag is reported as Changed, or, if it contains blocks itself (Section, List, ...), diffed
+// recursively so only the inner blocks that differ are reported.
+func Diff(old, new *Document) []Change {
+	return diffNodes("", old.Children, new.Children)
+}
+
+func diffNodes(path string, old, new []Node) []Change {
+	x, y := renderNodes(old), renderNodes(new)
+	oldPaths, newPaths := nodePaths(path, old), nodePaths(path, new)
+
+	var changes []Change
+	var removed, added []int
+	flush := func() {
+		changes = append(changes, pairGap(old, new, removed, added, oldPaths, newPaths, x, y)...)
+		removed, added = removed[:0], added[:0]
+	}
+	for _, op := range EditScript(x, y) {
+		switch op.Kind {
+		case ' ':
+			flush()
+		case '-':
+			removed = append(removed, op.Old)
+		case '+':
+			added = append(added, op.New)
+		}
+	}
+	flush()
+	return changes
+}
+
+// EditOp is one step of an edit script: ' ' (kept), '-' (only in the old sequence) or '+' (only in the new one).
+// Old and New are the zero-based positions reached in each sequence before the step, so a kept or removed item
+// is old[Old] and an added one is new[New].
+type EditOp struct {
+	Kind     byte
+	Old, New int
+}
+
+// EditScript computes a minimal edit script from old to new with a longest-common-subsequence table. It is
+// quadratic in time and memory, which is fine for email bodies of a few hundred lines or blocks.
+func EditScript(old, new []string) []EditOp {
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var ops []EditOp
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && old[i] == new[j]:
+			ops = append(ops, EditOp{' ', i, j})
+			i, j = i+1, j+1
+		case i < len(old) && (j == len(new) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, EditOp{'-', i, j})
+			i++
+		default:
+			ops = append(ops, EditOp{'+', i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// pairGap reports a run of removed and added siblings between two unchanged ones, pairing removed and added
+// elements with the same tag in order.
+func pairGap(old, new []Node, removed, added []int, oldPaths, newPaths, x, y []string) []Change {
+	var changes []Change
+	used := make([]bool, len(added))
+	for _, i := range removed {
+		oe, _ := old[i].(*Element)
+		pair := -1
+		for k, j := range added {
+			if ne, ok := new[j].(*Element); ok && !used[k] && oe != nil && ne.Name == oe.Name {
+				pair = k
+				break
+			}
+		}
+		if pair < 0 {
+			changes = append(changes, Change{Kind: Removed, Path: oldPaths[i], Old: x[i]})
+			continue
+		}
+		used[pair] = true
+		j := added[pair]
+		ne := new[j].(*Element)
+		if !allInline(oe.Children) && !allInline(ne.Children) && sameAttrs(oe, ne) {
+			changes = append(changes, diffNodes(newPaths[j], oe.Children, ne.Children)...)
+			continue
+		}
+		changes = append(changes, Change{Kind: Changed, Path: newPaths[j], Old: x[i], New: y[j]})
+	}
+	for k, j := range added {
+		if !used[k] {
+			changes = append(changes, Change{Kind: Added, Path: newPaths[j], New: y[j]})
+		}
+	}
+	return changes
+}
+
+func sameAttrs(a, b *Element) bool {
+	if len(a.Attrs) != len(b.Attrs) {
+		return false
+	}
+	for _, attr := range a.Attrs {
+		v, ok := b.Get(attr.Name)
+		if !ok || v != attr.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// renderNodes renders each node on its own, as a top-level block.
+func renderNodes(nodes []Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = (&Document{Children: []Node{n}}).String()
+	}
+	return out
+}
+
+// nodePaths returns the XPath-style path of each node under parent.
+func nodePaths(parent string, nodes []Node) []string {
+	counts := make(map[string]int)
+	paths := make([]string, len(nodes))
+	for i, n := range nodes {
+		name := "text()"
+		if e, ok := n.(*Element); ok {
+			name = e.Name
+		}
+		counts[name]++
+		paths[i] = fmt.Sprintf("%s/%s[%d]", parent, name, counts[name])
+	}
+	return paths
+}
+
+// Unified renders the diff in a unified-diff style, with a hunk per changed field or block:
+//
+//	--- old
+//	+++ new
+//	@@ subject @@
+//	-Welcome
+//	+Welcome aboard
+//	@@ /Section[1]/Text[2] @@
+//	-<Text>Hi</Text>
+//	+<Text>Hello</Text>
+//
+// It returns "" for an empty diff.
+func (d *MessageDiff) Unified(oldName, newName string) string {
+	if d.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, f := range d.Fields {
+		fmt.Fprintf(&b, "@@ %s @@\n", f.Field)
+		writePrefixed(&b, '-', f.Old)
+		writePrefixed(&b, '+', f.New)
+	}
+	for _, c := range d.Blocks {
+		fmt.Fprintf(&b, "@@ %s @@\n", c.Path)
+		if c.Kind != Added {
+			writePrefixed(&b, '-', c.Old)
+		}
+		if c.Kind != Removed {
+			writePrefixed(&b, '+', c.New)
+		}
+	}
+	return b.String()
+}
+
+// JSON renders the diff as indented JSON, with LMX left unescaped for readability.
+func (d *MessageDiff) JSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writePrefixed(b *strings.Builder, prefix byte, s string) {
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteByte(prefix)
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
@@ -0,0 +1,116 @@
+package lmx
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func TestDiffMessages(t *testing.T) {
+	old := &loops.EmailMessageResponse{Subject: "Welcome", FromName: "Team", LMX: `<Heading level="1">Hi</Heading>
+<Section>
+  <Text>One</Text>
+  <Text>Two</Text>
+  <Image src="a.png" alt="A" />
+</Section>
+<Divider />
+<Text>Bye</Text>`}
+	new := &loops.EmailMessageResponse{Subject: "Welcome aboard", FromName: "Team", LMX: `<Heading level="1">Hi</Heading>
+<Section><Text>One</Text><Text>Two <Bold>now</Bold></Text></Section>
+<Text>Bye</Text>
+<Button href="https://x.test">Go</Button>`}
+
+	d, err := DiffMessages(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- rev_1
++++ rev_2
+@@ subject @@
+-Welcome
++Welcome aboard
+@@ /Section[1]/Text[2] @@
+-<Text>Two</Text>
++<Text>Two <Bold>now</Bold></Text>
+@@ /Section[1]/Image[1] @@
+-<Image src="a.png" alt="A" />
+@@ /Divider[1] @@
+-<Divider />
+@@ /Button[1] @@
++<Button href="https://x.test">Go</Button>
+`
+	if got := d.Unified("rev_1", "rev_2"); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	b, err := d.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `{
+  "fields": [
+    {
+      "field": "subject",
+      "old": "Welcome",
+      "new": "Welcome aboard"
+    }
+  ],
+  "blocks": [
+    {
+      "kind": "changed",
+      "path": "/Section[1]/Text[2]",
+      "old": "<Text>Two</Text>",
+      "new": "<Text>Two <Bold>now</Bold></Text>"
+    },`
+	if !strings.HasPrefix(string(b), wantJSON) {
+		t.Errorf("JSON:\n%s", b)
+	}
+	var decoded MessageDiff
+	if err := json.Unmarshal(b, &decoded); err != nil || len(decoded.Blocks) != 4 {
+		t.Errorf("JSON does not round-trip: %v", err)
+	}
+	if d.Blocks[2].Kind != Removed || d.Blocks[3].Kind != Added {
+		t.Errorf("kinds: %+v", d.Blocks)
+	}
+}
+
+func TestDiff_AttributesAndReformatting(t *testing.T) {
+	a, _ := Parse("<Section>\n  <Text>x</Text>\n</Section>")
+	b, _ := Parse("<Section><Text>x</Text></Section>")
+	if changes := Diff(a, b); len(changes) != 0 {
+		t.Errorf("reformatting reported as change: %+v", changes)
+	}
+	// A container whose attributes change is reported whole.
+	a, _ = Parse(`<List><ListItem>a</ListItem></List>`)
+	b, _ = Parse(`<List ordered="true"><ListItem>a</ListItem></List>`)
+	if changes := Diff(a, b); len(changes) != 1 || changes[0].Kind != Changed || changes[0].Path != "/List[1]" {
+		t.Errorf("attribute change: %+v", changes)
+	}
+
+	d, err := DiffMessages(&loops.EmailMessageResponse{}, &loops.EmailMessageResponse{})
+	if err != nil || !d.Empty() || d.Unified("a", "b") != "" {
+		t.Errorf("empty diff: %+v, %v", d, err)
+	}
+	var syntaxErr *SyntaxError
+	if _, err := DiffMessages(&loops.EmailMessageResponse{}, &loops.EmailMessageResponse{LMX: "<Text>"}); !errors.As(err, &syntaxErr) {
+		t.Errorf("expected SyntaxError, got %v", err)
+	}
+}
+
+func TestEditScript(t *testing.T) {
+	old, new := []string{"a", "b", "c", "d"}, []string{"a", "c", "x", "d"}
+	var got []string
+	for _, op := range EditScript(old, new) {
+		item := old
+		i := op.Old
+		if op.Kind == '+' {
+			item, i = new, op.New
+		}
+		got = append(got, string(op.Kind)+item[i])
+	}
+	if want := " a -b  c +x  d"; strings.Join(got, " ") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, " "), want)
+	}
+}
@@ -0,0 +1,33 @@
+package lmx
+
+import (
+	"reflect"
+	"testing"
+)
+
+// FuzzFormat checks that Format is idempotent and that formatted source parses to the same tree as the input.
+func FuzzFormat(f *testing.F) {
+	f.Add("Hello {firstName}!")
+	f.Add("00 {0}0")
+	f.Add("a&#10;b")
+	f.Add("<Section>Hi <Bold>there</Bold><Divider /></Section>")
+	f.Add("<Section>\n  <Text>\n    a <Italic>b</Italic>\n    c\n  </Text>\n  <CodeBlock> x\n  y </CodeBlock>\n</Section>")
+	f.Add(`<Style backgroundColor="#fff" /><Text>Tips &amp; {"tricks"}</Text> after`)
+	f.Fuzz(func(t *testing.T, src string) {
+		doc, err := Parse(src)
+		if err != nil {
+			return
+		}
+		out := doc.String()
+		again, err := Parse(out)
+		if err != nil {
+			t.Fatalf("formatted source does not parse: %v\n%s", err, out)
+		}
+		if !reflect.DeepEqual(stripPos(again.Children), stripPos(doc.Children)) {
+			t.Fatalf("formatting changed the document:\n%q\n%q", src, out)
+		}
+		if twice := again.String(); twice != out {
+			t.Fatalf("Format is not idempotent:\n%q\n%q", out, twice)
+		}
+	})
+}
@@ -0,0 +1,340 @@
+package lmx
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// HTMLOptions configures RenderHTML.
+type HTMLOptions struct {
+	// Styles are the theme styles (ThemeResponse.Styles from GetTheme). <Style /> tags in the document override them.
+	Styles loops.ThemeStyles
+	// Data supplies sample values for <Variable /> tags and {name} expressions. Variables without a value use
+	// their fallback, or render empty.
+	Data map[string]interface{}
+	// Components maps component IDs to their LMX (ComponentResponse.LMX) so <Component /> references can be
+	// expanded. Unknown references render as an HTML comment.
+	Components map[string]string
+	// Subject and PreviewText fill the <title> and the hidden preheader.
+	Subject     string
+	PreviewText string
+	// Width is the content width in pixels (default 600).
+	Width int
+}
+
+// PreviewHTML parses LMX source and renders it with RenderHTML.
+func PreviewHTML(src string, opts HTMLOptions) (string, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return RenderHTML(doc, opts)
+}
+
+// RenderHTML renders doc as a standalone HTML email for previews and snapshot tests: a table-based layout with
+// all CSS inlined from the theme styles. It approximates, rather than reproduces, what Loops sends. The output
+// is deterministic.
+func RenderHTML(doc *Document, opts HTMLOptions) (string, error) {
+	r := &htmlRenderer{opts: opts, styles: opts.Styles}
+	// Style tags apply to the whole email wherever they appear, so collect them first.
+	r.collectStyles(doc.Children)
+	var body strings.Builder
+	if err := r.blocks(&body, doc.Children); err != nil {
+		return "", err
+	}
+
+	s := r.styles
+	width := opts.Width
+	if width <= 0 {
+		width = 600
+	}
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n</head>\n", html.EscapeString(opts.Subject))
+	fmt.Fprintf(&b, "<body style=\"%s\">\n", css("margin", "0", "padding", "0", "background-color", s.BackgroundColor))
+	if opts.PreviewText != "" {
+		fmt.Fprintf(&b, "<div style=\"display:none;max-height:0;overflow:hidden;\">%s</div>\n", html.EscapeString(opts.PreviewText))
+	}
+	fmt.Fprintf(&b, "<table role=\"presentation\" width=\"100%%\" cellpadding=\"0\" cellspacing=\"0\" border=\"0\" style=\"%s\">\n<tr><td align=\"center\" style=\"%s\">\n",
+		css("background-color", s.BackgroundColor),
+		css("padding", padding(s.BackgroundYPadding, s.BackgroundXPadding)))
+	fmt.Fprintf(&b, "<table role=\"presentation\" width=\"%d\" cellpadding=\"0\" cellspacing=\"0\" border=\"0\" style=\"%s\">\n<tr><td style=\"%s\">\n",
+		width,
+		css("max-width", px(float64(width)), "background-color", s.BodyColor, "border", border(s.BorderWidth, s.BorderColor), "border-radius", px(s.BorderRadius)),
+		css("padding", padding(s.BodyYPadding, s.BodyXPadding), "font-family", fontFamily(s), "color", s.TextBaseColor,
+			"font-size", px(s.TextBaseFontSize), "line-height", loops.LineHeightCSS(s.TextBaseLineHeight), "letter-spacing", px(s.TextBaseLetterSpacing)))
+	b.WriteString(body.String())
+	b.WriteString("</td></tr>\n</table>\n</td></tr>\n</table>\n</body>\n</html>\n")
+	return b.String(), nil
+}
+
+type htmlRenderer struct {
+	opts   HTMLOptions
+	styles loops.ThemeStyles
+	depth  int // component expansion depth, to stop reference cycles
+}
+
+func (r *htmlRenderer) collectStyles(nodes []Node) {
+	for _, n := range nodes {
+		if e, ok := n.(*Element); ok {
+			if e.Name == TagStyle {
+				applyStyleAttrs(&r.styles, e)
+			}
+			r.collectStyles(e.Children)
+		}
+	}
+}
+
+// applyStyleAttrs sets the ThemeStyles fields named by e's attributes; unknown or malformed values are ignored.
+func applyStyleAttrs(s *loops.ThemeStyles, e *Element) {
+	for _, f := range loops.ThemeStyleFields() {
+		if val, ok := e.Get(f.Name); ok {
+			f.Set(s, val)
+		}
+	}
+}
+
+func (r *htmlRenderer) blocks(b *strings.Builder, nodes []Node) error {
+	if allInline(nodes) {
+		if len(trimInline(append([]Node(nil), nodes...))) == 0 {
+			return nil
+		}
+		// Loose inline content at block level renders as a paragraph.
+		return r.block(b, Paragraph(nodes...))
+	}
+	for _, n := range nodes {
+		if err := r.block(b, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *htmlRenderer) block(b *strings.Builder, n Node) error {
+	switch n := n.(type) {
+	case Raw:
+		doc, err := Parse(string(n))
+		if err != nil {
+			return err
+		}
+		r.collectStyles(doc.Children)
+		return r.blocks(b, doc.Children)
+	case *Element:
+		return r.element(b, n)
+	default:
+		return r.blocks(b, []Node{n})
+	}
+}
+
+func (r *htmlRenderer) element(b *strings.Builder, e *Element) error {
+	s := r.styles
+	switch e.Name {
+	case TagStyle:
+		return nil
+	case TagHeading:
+		level, _ := strconv.Atoi(attr(e, "level"))
+		color, size, height, spacing := s.Heading1Color, s.Heading1FontSize, s.Heading1LineHeight, s.Heading1LetterSpacing
+		switch level {
+		case 2:
+			color, size, height, spacing = s.Heading2Color, s.Heading2FontSize, s.Heading2LineHeight, s.Heading2LetterSpacing
+		case 3:
+			color, size, height, spacing = s.Heading3Color, s.Heading3FontSize, s.Heading3LineHeight, s.Heading3LetterSpacing
+		default:
+			level = 1
+		}
+		fmt.Fprintf(b, "<h%d style=\"%s\">", level, css("margin", "0 0 16px", "color", color, "font-size", px(size), "line-height", loops.LineHeightCSS(height), "letter-spacing", px(spacing)))
+		r.inline(b, e.Children)
+		fmt.Fprintf(b, "</h%d>\n", level)
+	case TagText:
+		fmt.Fprintf(b, "<p style=\"%s\">", css("margin", "0 0 16px"))
+		r.inline(b, e.Children)
+		b.WriteString("</p>\n")
+	case TagButton:
+		fmt.Fprintf(b, "<table role=\"presentation\" cellpadding=\"0\" cellspacing=\"0\" border=\"0\" style=\"%s\"><tr><td style=\"%s\">",
+			css("margin", "0 0 16px"),
+			css("background-color", s.ButtonBodyColor, "border", border(s.ButtonBorderWidth, s.ButtonBorderColor), "border-radius", px(s.ButtonBorderRadius), "padding", padding(s.ButtonBodyYPadding, s.ButtonBodyXPadding)))
+		fmt.Fprintf(b, "<a href=\"%s\" style=\"%s\">", html.EscapeString(attr(e, "href")),
+			css("color", s.ButtonTextColor, "font-size", px(s.ButtonTextFontSize), "text-decoration", "none", "display", "inline-block"))
+		r.inline(b, e.Children)
+		b.WriteString("</a></td></tr></table>\n")
+	case TagImage:
+		fmt.Fprintf(b, "<img src=\"%s\" alt=\"%s\"", html.EscapeString(attr(e, "src")), html.EscapeString(attr(e, "alt")))
+		if w := attr(e, "width"); w != "" {
+			fmt.Fprintf(b, " width=\"%s\"", html.EscapeString(w))
+		}
+		fmt.Fprintf(b, " style=\"%s\">\n", css("display", "block", "max-width", "100%", "height", "auto", "border", "0", "margin", "0 0 16px"))
+	case TagDivider:
+		fmt.Fprintf(b, "<hr style=\"%s\">\n", css("border", "0", "border-top", border(max(s.DividerBorderWidth, 1), s.DividerColor), "margin", "16px 0"))
+	case TagList:
+		tag := "ul"
+		if v := attr(e, "ordered"); v == "true" {
+			tag = "ol"
+		}
+		fmt.Fprintf(b, "<%s style=\"%s\">\n", tag, css("margin", "0 0 16px", "padding-left", "24px"))
+		if err := r.blocks(b, e.Children); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "</%s>\n", tag)
+	case TagListItem:
+		b.WriteString("<li>")
+		if allInline(e.Children) {
+			r.inline(b, e.Children)
+		} else {
+			b.WriteByte('\n')
+			if err := r.blocks(b, e.Children); err != nil {
+				return err
+			}
+		}
+		b.WriteString("</li>\n")
+	case TagCodeBlock:
+		fmt.Fprintf(b, "<pre style=\"%s\"><code>", css("margin", "0 0 16px", "padding", "12px", "background-color", "#f4f4f5", "font-family", "Menlo, Consolas, monospace", "font-size", "13px", "white-space", "pre-wrap"))
+		r.inline(b, e.Children)
+		b.WriteString("</code></pre>\n")
+	case TagComponent:
+		id := attr(e, "id")
+		src, ok := r.opts.Components[id]
+		if !ok || r.depth > 8 {
+			fmt.Fprintf(b, "<!-- component %s not available -->\n", strings.ReplaceAll(id, "--", ""))
+			return nil
+		}
+		doc, err := Parse(src)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", id, err)
+		}
+		r.collectStyles(doc.Children)
+		r.depth++
+		defer func() { r.depth-- }()
+		return r.blocks(b, doc.Children)
+	default:
+		// Sections, unknown tags and stray inline tags: render content in a block container.
+		if IsInline(e.Name) {
+			return r.blocks(b, []Node{Paragraph(e)})
+		}
+		b.WriteString("<div>\n")
+		if err := r.blocks(b, e.Children); err != nil {
+			return err
+		}
+		b.WriteString("</div>\n")
+	}
+	return nil
+}
+
+func (r *htmlRenderer) inline(b *strings.Builder, nodes []Node) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case Text:
+			b.WriteString(html.EscapeString(string(n)))
+		case Expr:
+			name := strings.TrimSpace(string(n))
+			if strings.HasPrefix(name, "/*") {
+				continue
+			}
+			b.WriteString(html.EscapeString(r.variable(name, "")))
+		case Raw:
+			if doc, err := Parse(string(n)); err == nil {
+				r.inline(b, doc.Children)
+			}
+		case *Element:
+			r.inlineElement(b, n)
+		}
+	}
+}
+
+func (r *htmlRenderer) inlineElement(b *strings.Builder, e *Element) {
+	switch e.Name {
+	case TagBold:
+		b.WriteString("<strong>")
+		r.inline(b, e.Children)
+		b.WriteString("</strong>")
+	case TagItalic:
+		b.WriteString("<em>")
+		r.inline(b, e.Children)
+		b.WriteString("</em>")
+	case TagCode:
+		fmt.Fprintf(b, "<code style=\"%s\">", css("font-family", "Menlo, Consolas, monospace", "background-color", "#f4f4f5", "padding", "0 4px"))
+		r.inline(b, e.Children)
+		b.WriteString("</code>")
+	case TagLink:
+		fmt.Fprintf(b, "<a href=\"%s\" style=\"%s\">", html.EscapeString(attr(e, "href")), css("color", r.styles.TextLinkColor))
+		r.inline(b, e.Children)
+		b.WriteString("</a>")
+	case TagBreak:
+		b.WriteString("<br>")
+	case TagVariable:
+		b.WriteString(html.EscapeString(r.variable(attr(e, "name"), attr(e, "fallback"))))
+	default:
+		r.inline(b, e.Children)
+	}
+}
+
+func (r *htmlRenderer) variable(name, fallback string) string {
+	if v, ok := r.opts.Data[name]; ok && v != nil {
+		if s := fmt.Sprint(v); s != "" {
+			return s
+		}
+	}
+	return fallback
+}
+
+func attr(e *Element, name string) string {
+	v, _ := e.Get(name)
+	return v
+}
+
+// css joins property/value pairs into an inline style, skipping empty values.
+func css(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			continue
+		}
+		b.WriteString(pairs[i])
+		b.WriteByte(':')
+		b.WriteString(pairs[i+1])
+		b.WriteByte(';')
+	}
+	return html.EscapeString(b.String())
+}
+
+func px(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + "px"
+}
+
+func padding(y, x float64) string {
+	if y == 0 && x == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(y, 'f', -1, 64) + "px " + strconv.FormatFloat(x, 'f', -1, 64) + "px"
+}
+
+func border(width float64, color string) string {
+	if width == 0 || color == "" {
+		return ""
+	}
+	return px(width) + " solid " + color
+}
+
+func fontFamily(s loops.ThemeStyles) string {
+	generic := "sans-serif"
+	if strings.Contains(strings.ToLower(s.BodyFontCategory), "serif") && !strings.Contains(strings.ToLower(s.BodyFontCategory), "sans") {
+		generic = "serif"
+	} else if strings.Contains(strings.ToLower(s.BodyFontCategory), "mono") {
+		generic = "monospace"
+	}
+	if s.BodyFontFamily == "" {
+		return generic
+	}
+	family := s.BodyFontFamily
+	if strings.ContainsAny(family, " ") && !strings.ContainsAny(family, `'",`) {
+		family = "'" + family + "'"
+	}
+	return family + ", " + generic
+}
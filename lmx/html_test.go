@@ -0,0 +1,58 @@
+package lmx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func TestPreviewHTML(t *testing.T) {
+	src := `<Style textLinkColor="#0000ff" heading1FontSize="32" />
+<Heading level="1">Hi <Variable name="firstName" fallback="there" /></Heading>
+<Text>Plan: {plan} &lt;b&gt; <Link href="https://x.test/?a=1&amp;b=2">docs</Link>{/* note */}</Text>
+<Button href="https://x.test">Go</Button>
+<List><ListItem>one</ListItem></List>
+<Component id="cmp_footer" />
+<Component id="cmp_missing" />`
+	out, err := PreviewHTML(src, HTMLOptions{
+		Styles:      loops.ThemeStyles{BackgroundColor: "#f0f0f0", BodyFontFamily: "Open Sans", TextLinkColor: "#ff0000", ButtonBodyColor: "#111111"},
+		Data:        map[string]interface{}{"plan": "pro"},
+		Components:  map[string]string{"cmp_footer": "<Text>Footer <Variable name=\"plan\" /></Text>"},
+		Subject:     "Launch <day>",
+		PreviewText: "Big news",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Launch &lt;day&gt;</title>",
+		`<div style="display:none;max-height:0;overflow:hidden;">Big news</div>`,
+		"background-color:#f0f0f0;",
+		"font-family:&#39;Open Sans&#39;, sans-serif;",
+		`<h1 style="margin:0 0 16px;font-size:32px;">Hi there</h1>`,
+		`<p style="margin:0 0 16px;">Plan: pro &lt;b&gt; <a href="https://x.test/?a=1&amp;b=2" style="color:#0000ff;">docs</a></p>`,
+		`background-color:#111111;`,
+		`<a href="https://x.test" style="text-decoration:none;display:inline-block;">Go</a>`,
+		"<ul style=\"margin:0 0 16px;padding-left:24px;\">\n<li>one</li>\n</ul>",
+		"<p style=\"margin:0 0 16px;\">Footer pro</p>",
+		"<!-- component cmp_missing not available -->",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	again, _ := PreviewHTML(src, HTMLOptions{Data: map[string]interface{}{"plan": "pro"}})
+	if again2, _ := PreviewHTML(src, HTMLOptions{Data: map[string]interface{}{"plan": "pro"}}); again != again2 {
+		t.Error("output is not deterministic")
+	}
+}
+
+func TestPreviewHTML_SyntaxError(t *testing.T) {
+	if _, err := PreviewHTML("<Text>", HTMLOptions{}); err == nil {
+		t.Error("expected a syntax error")
+	}
+	if _, err := PreviewHTML(`<Component id="c" />`, HTMLOptions{Components: map[string]string{"c": "<Text>"}}); err == nil {
+		t.Error("expected an error for a malformed component")
+	}
+}
@@ -0,0 +1,143 @@
+package lmx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// Lint rule names reported in Issue.Rule.
+const (
+	RuleUnknownTag       = "unknown-tag"
+	RuleMissingAlt       = "missing-alt"
+	RuleMissingAttr      = "missing-attr"
+	RuleStyleContent     = "style-content"
+	RuleStylePlacement   = "style-placement"
+	RuleUnknownStyleAttr = "unknown-style-attr"
+	RuleStyleValue       = "style-value"
+	RuleHeadingLevel     = "heading-level"
+	RuleListItem         = "list-item"
+)
+
+// Issue is a lint finding.
+type Issue struct {
+	Pos     Position `json:"pos"`
+	Rule    string   `json:"rule"`
+	Message string   `json:"message"`
+}
+
+// String returns "line:col: message (rule)".
+func (i Issue) String() string { return i.Pos.String() + ": " + i.Message + " (" + i.Rule + ")" }
+
+var knownTags = map[string]bool{
+	TagStyle: true, TagSection: true, TagHeading: true, TagText: true, TagButton: true, TagImage: true,
+	TagDivider: true, TagList: true, TagListItem: true, TagCodeBlock: true, TagComponent: true,
+	TagBold: true, TagItalic: true, TagLink: true, TagCode: true, TagBreak: true, TagVariable: true,
+}
+
+// requiredAttrs lists attributes a tag is useless without.
+var requiredAttrs = map[string][]string{
+	TagButton:    {"href"},
+	TagLink:      {"href"},
+	TagImage:     {"src"},
+	TagVariable:  {"name"},
+	TagComponent: {"id"},
+}
+
+var styleFields = func() map[string]loops.ThemeStyleField {
+	m := make(map[string]loops.ThemeStyleField)
+	for _, f := range loops.ThemeStyleFields() {
+		m[f.Name] = f
+	}
+	return m
+}()
+
+// Lint checks doc for common mistakes the API would reject or render badly: unknown tags, images without alt
+// text, <Style> tags with content, outside the top level or repeated, style attributes that are not ThemeStyles
+// fields or have non-numeric values for numeric fields, missing required attributes, heading levels outside 1–3
+// and list items outside a List. Raw nodes are not inspected.
+func Lint(doc *Document) []Issue {
+	l := &linter{}
+	for _, n := range doc.Children {
+		l.node(n, nil, true)
+	}
+	return l.issues
+}
+
+// LintString parses src and lints it. Syntax errors are returned as a *SyntaxError.
+func LintString(src string) ([]Issue, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return Lint(doc), nil
+}
+
+type linter struct {
+	issues []Issue
+	styles int
+}
+
+func (l *linter) add(e *Element, rule, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{Pos: e.Pos, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) node(n Node, parent *Element, top bool) {
+	e, ok := n.(*Element)
+	if !ok {
+		return
+	}
+	if !knownTags[e.Name] {
+		l.add(e, RuleUnknownTag, "unknown tag <%s>", e.Name)
+	}
+	for _, name := range requiredAttrs[e.Name] {
+		if v, ok := e.Get(name); !ok || v == "" {
+			l.add(e, RuleMissingAttr, "<%s> is missing %s", e.Name, name)
+		}
+	}
+	switch e.Name {
+	case TagStyle:
+		l.style(e, top)
+	case TagImage:
+		if alt, ok := e.Get("alt"); !ok || alt == "" {
+			l.add(e, RuleMissingAlt, "<Image> has no alt text")
+		}
+	case TagHeading:
+		if v, ok := e.Get("level"); ok {
+			if n, err := strconv.Atoi(v); err != nil || n < 1 || n > 3 {
+				l.add(e, RuleHeadingLevel, "heading level %q must be 1, 2 or 3", v)
+			}
+		}
+	case TagListItem:
+		if parent == nil || parent.Name != TagList {
+			l.add(e, RuleListItem, "<ListItem> must be inside <List>")
+		}
+	}
+	for _, c := range e.Children {
+		l.node(c, e, false)
+	}
+}
+
+func (l *linter) style(e *Element, top bool) {
+	if len(e.Children) > 0 {
+		l.add(e, RuleStyleContent, "<Style> must be self-closing; close it with /> rather than wrapping content")
+	}
+	if !top {
+		l.add(e, RuleStylePlacement, "<Style> should be at the top level of the email")
+	}
+	if l.styles++; l.styles == 2 {
+		l.add(e, RuleStylePlacement, "more than one <Style> tag; later ones override earlier ones")
+	}
+	for _, a := range e.Attrs {
+		f, ok := styleFields[a.Name]
+		switch {
+		case !ok:
+			l.add(e, RuleUnknownStyleAttr, "unknown style attribute %s", a.Name)
+		case f.Numeric() && !a.Expr:
+			if _, err := strconv.ParseFloat(a.Value, 64); err != nil {
+				l.add(e, RuleStyleValue, "style attribute %s must be a number, got %q", a.Name, a.Value)
+			}
+		}
+	}
+}
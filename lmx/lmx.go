@@ -0,0 +1,236 @@
+// Package lmx builds LMX, the JSX-like markup Loops uses for email message and component bodies
+// (UpdateEmailMessageRequest.LMX, Component.LMX).
+//
+// Documents are trees of Nodes: *Element for tags, Text for escaped character data, Expr for {expressions} and
+// Raw for LMX inserted verbatim (such as a component body fetched with GetComponent). Constructors cover the
+// common tags; the output of Document.String is well-formed and stable. Parse reads LMX back into a Document,
+// Lint checks it for common mistakes and Format normalises it for stable diffs.
+//
+//	doc := lmx.NewDocument(
+//		lmx.Style(theme.Styles),
+//		lmx.Heading(1, lmx.Text("Welcome")),
+//		lmx.Paragraph(lmx.Text("Hi "), lmx.Variable("firstName", "there"), lmx.Text("!")),
+//		lmx.Button("https://example.com", "Get started"),
+//	)
+//	req := &loops.UpdateEmailMessageRequest{LMX: doc.String()}
+package lmx
+
+import (
+	"strconv"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// Tag names understood by this package.
+const (
+	TagStyle     = "Style"
+	TagSection   = "Section"
+	TagHeading   = "Heading"
+	TagText      = "Text"
+	TagButton    = "Button"
+	TagImage     = "Image"
+	TagDivider   = "Divider"
+	TagList      = "List"
+	TagListItem  = "ListItem"
+	TagCodeBlock = "CodeBlock"
+	TagComponent = "Component"
+	TagBold      = "Bold"
+	TagItalic    = "Italic"
+	TagLink      = "Link"
+	TagCode      = "Code"
+	TagBreak     = "Break"
+	TagVariable  = "Variable"
+)
+
+// inlineTags are rendered within a line of text; all other tags start their own line.
+var inlineTags = map[string]bool{
+	TagBold: true, TagItalic: true, TagLink: true, TagCode: true, TagBreak: true, TagVariable: true,
+}
+
+// IsInline reports whether tag is an inline (phrasing) tag such as Bold or Link.
+func IsInline(tag string) bool { return inlineTags[tag] }
+
+// Node is an LMX node: *Element, Text, Expr or Raw.
+type Node interface {
+	node()
+}
+
+// Text is character data. It is escaped when rendered.
+type Text string
+
+// Expr is a {expression}, stored without the braces and rendered verbatim.
+type Expr string
+
+// Raw is LMX inserted verbatim, e.g. a component body. It must itself be well-formed.
+type Raw string
+
+// Attr is an element attribute. When Expr is set, Value is an expression rendered as name={Value}.
+type Attr struct {
+	Name  string
+	Value string
+	Expr  bool
+}
+
+// Position is a 1-based line and column (in characters) in LMX source.
+type Position struct {
+	Line int
+	Col  int
+}
+
+// String returns "line:col".
+func (p Position) String() string { return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Col) }
+
+// Element is an LMX tag with attributes (in order) and children. Pos is set by Parse.
+type Element struct {
+	Name     string
+	Attrs    []Attr
+	Children []Node
+	Pos      Position
+}
+
+func (Text) node()     {}
+func (Expr) node()     {}
+func (Raw) node()      {}
+func (*Element) node() {}
+
+// El returns an element with the given name and children.
+func El(name string, children ...Node) *Element {
+	return &Element{Name: name, Children: children}
+}
+
+// Attr sets attribute name to the string value, replacing an existing value, and returns e for chaining.
+func (e *Element) Attr(name, value string) *Element {
+	for i := range e.Attrs {
+		if e.Attrs[i].Name == name {
+			e.Attrs[i] = Attr{Name: name, Value: value}
+			return e
+		}
+	}
+	e.Attrs = append(e.Attrs, Attr{Name: name, Value: value})
+	return e
+}
+
+// Get returns the value of attribute name.
+func (e *Element) Get(name string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// Append adds children and returns e for chaining.
+func (e *Element) Append(children ...Node) *Element {
+	e.Children = append(e.Children, children...)
+	return e
+}
+
+// Document is a complete LMX body.
+type Document struct {
+	Children []Node
+}
+
+// NewDocument returns a document with the given top-level nodes.
+func NewDocument(children ...Node) *Document {
+	return &Document{Children: children}
+}
+
+// Add appends top-level nodes and returns d for chaining.
+func (d *Document) Add(children ...Node) *Document {
+	d.Children = append(d.Children, children...)
+	return d
+}
+
+// Style returns a <Style /> tag carrying every field set on styles, named as in ThemeStyles' JSON tags (which are
+// the attribute names the tag accepts).
+func Style(styles loops.ThemeStyles) *Element {
+	e := El(TagStyle)
+	for _, f := range loops.ThemeStyleFields() {
+		if v, ok := f.Get(styles); ok {
+			e.Attr(f.Name, v)
+		}
+	}
+	return e
+}
+
+// StylesOf returns the theme styles set by the attributes of a <Style /> element; it is the inverse of Style.
+// Unknown or malformed attributes are ignored.
+func StylesOf(e *Element) loops.ThemeStyles {
+	var s loops.ThemeStyles
+	applyStyleAttrs(&s, e)
+	return s
+}
+
+// Section groups blocks.
+func Section(children ...Node) *Element { return El(TagSection, children...) }
+
+// Heading returns a heading of the given level (1–3, matching the theme's heading styles).
+func Heading(level int, children ...Node) *Element {
+	return El(TagHeading, children...).Attr("level", strconv.Itoa(level))
+}
+
+// Paragraph returns a <Text> block.
+func Paragraph(children ...Node) *Element { return El(TagText, children...) }
+
+// Button returns a button linking to href.
+func Button(href, label string) *Element {
+	return El(TagButton, Text(label)).Attr("href", href)
+}
+
+// Image returns an image. alt should describe the image for clients that block images.
+func Image(src, alt string) *Element {
+	return El(TagImage).Attr("src", src).Attr("alt", alt)
+}
+
+// Divider returns a horizontal rule.
+func Divider() *Element { return El(TagDivider) }
+
+// List returns a bulleted (or numbered, if ordered) list of ListItems.
+func List(ordered bool, items ...Node) *Element {
+	e := El(TagList, items...)
+	if ordered {
+		e.Attr("ordered", "true")
+	}
+	return e
+}
+
+// ListItem returns a list item.
+func ListItem(children ...Node) *Element { return El(TagListItem, children...) }
+
+// CodeBlock returns a preformatted code block.
+func CodeBlock(code string) *Element { return El(TagCodeBlock, Text(code)) }
+
+// ComponentRef references a saved component by ID, so later edits to the component apply to this email.
+func ComponentRef(componentID string) *Element {
+	return El(TagComponent).Attr("id", componentID)
+}
+
+// FromComponent inlines the body of a component fetched with GetComponent.
+func FromComponent(c *loops.ComponentResponse) Raw { return Raw(c.LMX) }
+
+// Bold returns bold inline content.
+func Bold(children ...Node) *Element { return El(TagBold, children...) }
+
+// Italic returns italic inline content.
+func Italic(children ...Node) *Element { return El(TagItalic, children...) }
+
+// Link returns an inline link to href.
+func Link(href string, children ...Node) *Element {
+	return El(TagLink, children...).Attr("href", href)
+}
+
+// Code returns inline code.
+func Code(code string) *Element { return El(TagCode, Text(code)) }
+
+// Break returns a line break.
+func Break() *Element { return El(TagBreak) }
+
+// Variable inserts the contact or event property name, or fallback when it is empty.
+func Variable(name, fallback string) *Element {
+	e := El(TagVariable).Attr("name", name)
+	if fallback != "" {
+		e.Attr("fallback", fallback)
+	}
+	return e
+}
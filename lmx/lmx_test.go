@@ -0,0 +1,63 @@
+package lmx
+
+import (
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func TestDocument_String(t *testing.T) {
+	doc := NewDocument(
+		Style(loops.ThemeStyles{BackgroundColor: "#fff", BodyXPadding: 24, TextBaseLineHeight: 1.5}),
+		Heading(1, Text("Tips & tricks")),
+		Section(
+			Paragraph(Text("Hi "), Variable("firstName", "there"), Text(", use {braces} <safely>.")),
+			Image("https://example.com/a.png", `Say "hi"`).Attr("width", "600"),
+			List(true, ListItem(Bold(Text("one"))), ListItem(Link("https://example.com?a=1&b=2", Text("two")))),
+		),
+		Divider(),
+		Button("https://example.com", "Go"),
+	)
+	want := `<Style backgroundColor="#fff" bodyXPadding="24" textBaseLineHeight="1.5" />
+<Heading level="1">Tips &amp; tricks</Heading>
+<Section>
+  <Text>Hi <Variable name="firstName" fallback="there" />, use &#123;braces&#125; &lt;safely&gt;.</Text>
+  <Image src="https://example.com/a.png" alt="Say &quot;hi&quot;" width="600" />
+  <List ordered="true">
+    <ListItem><Bold>one</Bold></ListItem>
+    <ListItem><Link href="https://example.com?a=1&amp;b=2">two</Link></ListItem>
+  </List>
+</Section>
+<Divider />
+<Button href="https://example.com">Go</Button>`
+	if got := doc.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFromComponent(t *testing.T) {
+	footer := &loops.ComponentResponse{ComponentID: "cmp_1", Name: "Footer", LMX: "<Text>Footer</Text>\n<Divider />\n"}
+	doc := NewDocument(Section(FromComponent(footer), ComponentRef("cmp_2")))
+	want := "<Section>\n  <Text>Footer</Text>\n  <Divider />\n  <Component id=\"cmp_2\" />\n</Section>"
+	if got := doc.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestElement_AttrReplaces(t *testing.T) {
+	e := Button("a", "Go").Attr("href", "b")
+	if v, _ := e.Get("href"); v != "b" || len(e.Attrs) != 1 {
+		t.Errorf("attrs: %+v", e.Attrs)
+	}
+}
+
+func TestStylesOf(t *testing.T) {
+	styles := loops.ThemeStyles{BackgroundColor: "#fff", BodyXPadding: 24, TextBaseLineHeight: 1.5}
+	if got := StylesOf(Style(styles)); got != styles {
+		t.Errorf("round trip: %+v", got)
+	}
+	e := El(TagStyle).Attr("bodyColor", "#eee").Attr("bodyXPadding", "wide").Attr("bogus", "1")
+	if got := StylesOf(e); got != (loops.ThemeStyles{BodyColor: "#eee"}) {
+		t.Errorf("malformed attributes: %+v", got)
+	}
+}
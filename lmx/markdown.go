@@ -0,0 +1,566 @@
+package lmx
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+// MarkdownOptions configures FromMarkdown.
+type MarkdownOptions struct {
+	// Styles, if set, adds a <Style /> tag with these styles first, e.g. ThemeResponse.Styles from GetTheme.
+	Styles *loops.ThemeStyles
+}
+
+// FromMarkdown converts CommonMark to an LMX document suitable for UpdateEmailMessageRequest.LMX.
+//
+// Supported: ATX and setext headings (levels 4–6 become level 3), paragraphs, emphasis and strong emphasis,
+// inline code, fenced and indented code blocks, bullet and ordered lists (nested), block quotes (as a Section),
+// thematic breaks, inline links, autolinks, images and hard line breaks. Images become block-level <Image> tags,
+// splitting the paragraph around them. Reference links, raw HTML and other extensions are kept as text.
+func FromMarkdown(src string, opts MarkdownOptions) *Document {
+	doc := NewDocument()
+	if opts.Styles != nil {
+		doc.Add(Style(*opts.Styles))
+	}
+	src = strings.ReplaceAll(strings.ReplaceAll(src, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(src, "\n")
+	for i, l := range lines {
+		lines[i] = expandTabs(l)
+	}
+	return doc.Add(mdBlocks(lines)...)
+}
+
+var (
+	mdATX      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdBreak    = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdSetext1  = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	mdSetext2  = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	mdFence    = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*?)[ \t]*$")
+	mdListItem = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])( +|$)(.*)$`)
+)
+
+func expandTabs(l string) string {
+	if !strings.Contains(l, "\t") {
+		return l
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range l {
+		if r == '\t' {
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
+
+func blank(l string) bool { return strings.TrimSpace(l) == "" }
+
+func indentOf(l string) int { return len(l) - len(strings.TrimLeft(l, " ")) }
+
+type listMarker struct {
+	ordered bool
+	char    byte // bullet character, or '.' / ')' for ordered lists
+	start   int
+	width   int // columns up to the item content
+	rest    string
+}
+
+func parseListMarker(l string) (listMarker, bool) {
+	m := mdListItem.FindStringSubmatch(l)
+	if m == nil {
+		return listMarker{}, false
+	}
+	mk := listMarker{rest: m[4], width: len(m[1]) + len(m[2]) + len(m[3])}
+	if m[3] == "" || len(m[3]) > 4 {
+		// No content on the marker line, or indented code after it: content starts one column after the marker.
+		mk.width = len(m[1]) + len(m[2]) + 1
+		mk.rest = strings.Repeat(" ", max(len(m[3])-1, 0)) + m[4]
+	}
+	marker := m[2]
+	mk.char = marker[len(marker)-1]
+	if mk.char >= '0' && mk.char <= '9' || mk.char == '.' || mk.char == ')' {
+		mk.ordered = true
+		mk.start, _ = strconv.Atoi(marker[:len(marker)-1])
+	}
+	return mk, true
+}
+
+// startsBlock reports whether l begins a block that interrupts a paragraph.
+func startsBlock(l string) bool {
+	if _, ok := parseListMarker(l); ok {
+		return true
+	}
+	t := strings.TrimLeft(l, " ")
+	return indentOf(l) < 4 && (mdATX.MatchString(l) || mdBreak.MatchString(l) || mdFence.MatchString(l) || strings.HasPrefix(t, ">"))
+}
+
+func mdBlocks(lines []string) []Node {
+	var out []Node
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			out = append(out, mdParagraph(para)...)
+			para = nil
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case blank(line):
+			flush()
+		case indentOf(line) >= 4 && len(para) == 0:
+			var code []string
+			for ; i < len(lines) && (blank(lines[i]) || indentOf(lines[i]) >= 4); i++ {
+				if len(lines[i]) >= 4 {
+					code = append(code, lines[i][4:])
+				} else {
+					code = append(code, "")
+				}
+			}
+			i--
+			for len(code) > 0 && blank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			out = append(out, CodeBlock(strings.Join(code, "\n")))
+		case mdFence.MatchString(line):
+			flush()
+			m := mdFence.FindStringSubmatch(line)
+			indent, fence, info := len(m[1]), m[2], m[3]
+			var code []string
+			for i++; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if indentOf(lines[i]) < 4 && strings.HasPrefix(t, fence[:1]) && strings.Trim(t, fence[:1]) == "" && len(t) >= len(fence) {
+					break
+				}
+				l := lines[i]
+				if n := indentOf(l); n > 0 {
+					l = l[min(n, indent):]
+				}
+				code = append(code, l)
+			}
+			cb := CodeBlock(strings.Join(code, "\n"))
+			if lang, _, _ := strings.Cut(info, " "); lang != "" {
+				cb.Attr("language", lang)
+			}
+			out = append(out, cb)
+		case len(para) > 0 && mdSetext1.MatchString(line):
+			out = append(out, Heading(1, mdInline(strings.Join(para, "\n"))...))
+			para = nil
+		case len(para) > 0 && mdSetext2.MatchString(line):
+			out = append(out, Heading(2, mdInline(strings.Join(para, "\n"))...))
+			para = nil
+		case mdBreak.MatchString(line):
+			flush()
+			out = append(out, Divider())
+		case mdATX.MatchString(line):
+			flush()
+			m := mdATX.FindStringSubmatch(line)
+			out = append(out, Heading(min(len(m[1]), 3), mdInline(m[2])...))
+		case indentOf(line) < 4 && strings.HasPrefix(strings.TrimLeft(line, " "), ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && !blank(lines[i]); i++ {
+				t := strings.TrimLeft(lines[i], " ")
+				if strings.HasPrefix(t, ">") {
+					t = strings.TrimPrefix(t[1:], " ")
+				} else if len(quoted) == 0 || startsBlock(lines[i]) {
+					break
+				}
+				quoted = append(quoted, t)
+			}
+			i--
+			out = append(out, Section(mdBlocks(quoted)...))
+		default:
+			if _, ok := parseListMarker(line); ok {
+				flush()
+				var list Node
+				list, i = mdList(lines, i)
+				out = append(out, list)
+				i--
+				continue
+			}
+			para = append(para, strings.TrimLeft(line, " "))
+		}
+	}
+	flush()
+	return out
+}
+
+// mdList parses the list starting at lines[i] and returns it with the index of the first line after it.
+func mdList(lines []string, i int) (Node, int) {
+	first, _ := parseListMarker(lines[i])
+	list := List(first.ordered)
+	if first.ordered && first.start != 1 {
+		list.Attr("start", strconv.Itoa(first.start))
+	}
+	for i < len(lines) {
+		mk, ok := parseListMarker(lines[i])
+		if !ok || mk.ordered != first.ordered || mk.char != first.char {
+			break
+		}
+		content := []string{mk.rest}
+		for i++; i < len(lines); i++ {
+			l := lines[i]
+			if blank(l) {
+				k := i
+				for k < len(lines) && blank(lines[k]) {
+					k++
+				}
+				if k < len(lines) && indentOf(lines[k]) >= mk.width {
+					for ; i < k; i++ {
+						content = append(content, "")
+					}
+					i--
+					continue
+				}
+				break
+			}
+			if indentOf(l) >= mk.width {
+				content = append(content, l[mk.width:])
+				continue
+			}
+			if startsBlock(l) || blank(content[len(content)-1]) {
+				break
+			}
+			content = append(content, strings.TrimLeft(l, " ")) // lazy continuation
+		}
+		list.Append(ListItem(unwrapParagraph(mdBlocks(content))...))
+		k := i
+		for k < len(lines) && blank(lines[k]) {
+			k++
+		}
+		if next, ok := parseListMarker(safeLine(lines, k)); !ok || next.ordered != first.ordered || next.char != first.char {
+			break
+		}
+		i = k
+	}
+	return list, i
+}
+
+func safeLine(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// unwrapParagraph keeps tight list items inline: a lone paragraph is replaced by its content.
+func unwrapParagraph(blocks []Node) []Node {
+	if len(blocks) == 1 {
+		if p, ok := blocks[0].(*Element); ok && p.Name == TagText {
+			return p.Children
+		}
+	}
+	return blocks
+}
+
+// mdParagraph converts paragraph lines to Text blocks, split around images.
+func mdParagraph(lines []string) []Node {
+	var out []Node
+	var seg []Node
+	flush := func() {
+		seg = trimInline(seg)
+		if len(seg) > 0 {
+			out = append(out, Paragraph(seg...))
+		}
+		seg = nil
+	}
+	for _, n := range mdInline(strings.Join(lines, "\n")) {
+		if e, ok := n.(*Element); ok && e.Name == TagImage {
+			flush()
+			out = append(out, e)
+			continue
+		}
+		seg = append(seg, n)
+	}
+	flush()
+	return out
+}
+
+func trimInline(nodes []Node) []Node {
+	if len(nodes) > 0 {
+		if t, ok := nodes[0].(Text); ok {
+			nodes[0] = Text(strings.TrimLeft(string(t), " \n"))
+		}
+		if t, ok := nodes[len(nodes)-1].(Text); ok {
+			nodes[len(nodes)-1] = Text(strings.TrimRight(string(t), " \n"))
+		}
+	}
+	out := nodes[:0]
+	for _, n := range nodes {
+		if n != Text("") {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+var mdAutolink = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*|[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*)>`)
+
+// mdInline converts inline Markdown to nodes.
+func mdInline(s string) []Node {
+	var out []Node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, Text(text.String()))
+			text.Reset()
+		}
+	}
+	emit := func(n Node) {
+		flush()
+		out = append(out, n)
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			emit(Break())
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '\n':
+			// Two or more trailing spaces make a hard break; otherwise a soft break is a space.
+			cur := strings.TrimRight(text.String(), " ")
+			hard := text.Len()-len(cur) >= 2
+			text.Reset()
+			text.WriteString(cur)
+			if hard {
+				emit(Break())
+			} else {
+				text.WriteByte(' ')
+			}
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+			continue
+		case c == '`':
+			if code, n, ok := mdCodeSpan(s[i:]); ok {
+				emit(Code(code))
+				i += n
+				continue
+			}
+			n := runLength(s[i:], '`')
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		case c == '<':
+			if m := mdAutolink.FindStringSubmatch(s[i:]); m != nil {
+				href := m[1]
+				if !strings.Contains(href, ":") {
+					href = "mailto:" + href
+				}
+				emit(Link(href, Text(m[1])))
+				i += len(m[0])
+				continue
+			}
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if label, dest, n, ok := mdLink(s[i+1:]); ok {
+				emit(Image(dest, plainText(label)))
+				i += 1 + n
+				continue
+			}
+		case c == '[':
+			if label, dest, n, ok := mdLink(s[i:]); ok {
+				emit(Link(dest, mdInline(label)...))
+				i += n
+				continue
+			}
+		case c == '*' || c == '_':
+			if node, n, ok := mdEmphasis(s, i); ok {
+				emit(node)
+				i += n
+				continue
+			}
+			n := runLength(s[i:], c)
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return out
+}
+
+func isASCIIPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+// mdCodeSpan parses a code span at the start of s.
+func mdCodeSpan(s string) (code string, n int, ok bool) {
+	open := runLength(s, '`')
+	for j := open; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		run := runLength(s[j:], '`')
+		if run == open {
+			code = strings.ReplaceAll(s[open:j], "\n", " ")
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			return code, j + run, true
+		}
+		j += run
+	}
+	return "", 0, false
+}
+
+// mdLink parses [label](destination "title") at the start of s.
+func mdLink(s string) (label, dest string, n int, ok bool) {
+	depth := 0
+	end := -1
+	for j := 0; j < len(s) && end < 0; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				end = j
+			}
+		}
+	}
+	if end < 0 || end+1 >= len(s) || s[end+1] != '(' {
+		return "", "", 0, false
+	}
+	label = s[1:end]
+	j := end + 2
+	for j < len(s) && (s[j] == ' ' || s[j] == '\n') {
+		j++
+	}
+	if j < len(s) && s[j] == '<' {
+		k := strings.IndexAny(s[j:], ">\n")
+		if k < 0 || s[j+k] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[j+1 : j+k]
+		j += k + 1
+	} else {
+		start, parens := j, 0
+		for ; j < len(s) && s[j] > ' '; j++ {
+			if s[j] == '(' {
+				parens++
+			} else if s[j] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			}
+		}
+		dest = s[start:j]
+	}
+	for j < len(s) && (s[j] == ' ' || s[j] == '\n') {
+		j++
+	}
+	if j < len(s) && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		k := strings.IndexByte(s[j+1:], closer)
+		if k < 0 {
+			return "", "", 0, false
+		}
+		j += k + 2
+		for j < len(s) && (s[j] == ' ' || s[j] == '\n') {
+			j++
+		}
+	}
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return label, dest, j + 1, true
+}
+
+// mdEmphasis parses *em*, **strong** or ***both*** (or the underscore forms) starting at s[i].
+func mdEmphasis(s string, i int) (Node, int, bool) {
+	c := s[i]
+	run := runLength(s[i:], c)
+	if run > 3 {
+		return nil, 0, false
+	}
+	inner := i + run
+	if inner >= len(s) || s[inner] == ' ' || s[inner] == '\n' {
+		return nil, 0, false
+	}
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return nil, 0, false
+	}
+	for n := run; n >= 1; n-- {
+		delim := strings.Repeat(string(c), n)
+		for j := i + n; j < len(s); {
+			k := strings.Index(s[j:], delim)
+			if k < 0 {
+				break
+			}
+			j += k
+			closeRun := runLength(s[j:], c)
+			if j > i+n && s[j-1] != ' ' && s[j-1] != '\n' && closeRun == n &&
+				!(c == '_' && j+n < len(s) && isWordByte(s[j+n])) {
+				body := s[i+n : j]
+				var node Node
+				switch n {
+				case 1:
+					node = Italic(mdInline(body)...)
+				case 2:
+					node = Bold(mdInline(body)...)
+				default:
+					node = Bold(Italic(mdInline(body)...))
+				}
+				return node, j + n - i, true
+			}
+			j += closeRun
+		}
+	}
+	return nil, 0, false
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// plainText flattens inline Markdown to text, for image alt text.
+func plainText(s string) string {
+	var b strings.Builder
+	var walk func([]Node)
+	walk = func(nodes []Node) {
+		for _, n := range nodes {
+			switch n := n.(type) {
+			case Text:
+				b.WriteString(string(n))
+			case *Element:
+				if alt, ok := n.Get("alt"); ok && n.Name == TagImage {
+					b.WriteString(alt)
+				}
+				walk(n.Children)
+			}
+		}
+	}
+	walk(mdInline(s))
+	return b.String()
+}
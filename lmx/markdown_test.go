@@ -0,0 +1,78 @@
+package lmx
+
+import (
+	"testing"
+
+	"github.com/Whats-A-MattR/loops-go-sdk"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	src := "# Launch *week*\n" +
+		"\n" +
+		"Hello **team**, read the [docs](https://x.test/docs \"Docs\") or mail <hi@x.test>.  \n" +
+		"Use `go test` and 2 < 3 {ok}.\n" +
+		"\n" +
+		"![Hero shot](https://x.test/hero.png)\n" +
+		"\n" +
+		"- one\n" +
+		"- two\n" +
+		"  continued\n" +
+		"  1. nested\n" +
+		"\n" +
+		"> quoted _text_\n" +
+		"\n" +
+		"---\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"<hi>\")\n" +
+		"```\n" +
+		"Sub\n" +
+		"===\n" +
+		"#### Deep\n"
+	doc := FromMarkdown(src, MarkdownOptions{Styles: &loops.ThemeStyles{TextLinkColor: "#00f"}})
+	want := `<Style textLinkColor="#00f" />
+<Heading level="1">Launch <Italic>week</Italic></Heading>
+<Text>Hello <Bold>team</Bold>, read the <Link href="https://x.test/docs">docs</Link> or mail <Link href="mailto:hi@x.test">hi@x.test</Link>.<Break />Use <Code>go test</Code> and 2 &lt; 3 &#123;ok&#125;.</Text>
+<Image src="https://x.test/hero.png" alt="Hero shot" />
+<List>
+  <ListItem>one</ListItem>
+  <ListItem>
+    <Text>two continued</Text>
+    <List ordered="true">
+      <ListItem>nested</ListItem>
+    </List>
+  </ListItem>
+</List>
+<Section>
+  <Text>quoted <Italic>text</Italic></Text>
+</Section>
+<Divider />
+<CodeBlock language="go">fmt.Println("&lt;hi&gt;")</CodeBlock>
+<Heading level="1">Sub</Heading>
+<Heading level="3">Deep</Heading>`
+	if got := doc.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if issues := Lint(doc); len(issues) != 0 {
+		t.Errorf("converted Markdown has lint issues: %v", issues)
+	}
+	if _, err := Parse(doc.String()); err != nil {
+		t.Errorf("converted Markdown does not parse: %v", err)
+	}
+}
+
+func TestFromMarkdown_Inline(t *testing.T) {
+	tests := []struct{ md, want string }{
+		{"snake_case_name and 2 * 3 * 4", "<Text>snake_case_name and 2 * 3 * 4</Text>"},
+		{"***both*** and __strong__", "<Text><Bold><Italic>both</Italic></Bold> and <Bold>strong</Bold></Text>"},
+		{`\*not em\* and [not a link]`, "<Text>*not em* and [not a link]</Text>"},
+		{"text ![a *b*](i.png) more", "<Text>text</Text>\n<Image src=\"i.png\" alt=\"a b\" />\n<Text>more</Text>"},
+		{"1. a\n\n2. b", "<List ordered=\"true\">\n  <ListItem>a</ListItem>\n  <ListItem>b</ListItem>\n</List>"},
+		{"    indented\n    code", "<CodeBlock>indented\ncode</CodeBlock>"},
+	}
+	for _, tt := range tests {
+		if got := FromMarkdown(tt.md, MarkdownOptions{}).String(); got != tt.want {
+			t.Errorf("FromMarkdown(%q):\n%s\nwant:\n%s", tt.md, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package lmx
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError is a malformed-LMX error with the position it was detected at.
+type SyntaxError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return "lmx: " + e.Pos.String() + ": " + e.Msg
+}
+
+// voidTags never have content; a missing "/>" on one of them usually explains an unclosed-tag error.
+var voidTags = map[string]bool{
+	TagStyle: true, TagImage: true, TagDivider: true, TagBreak: true, TagVariable: true, TagComponent: true,
+}
+
+// Parse parses LMX source, such as EmailMessageResponse.LMX or Component.LMX, into a Document with element
+// positions set. Character references (&amp;, &#123;, ...) in text and quoted attribute values are decoded.
+// Whitespace follows JSX rules: text is trimmed around line breaks and whitespace-only lines are dropped, except
+// inside CodeBlock. Malformed input returns a *SyntaxError.
+func Parse(src string) (*Document, error) {
+	p := &parser{src: src, lines: []int{0}}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+	nodes, err := p.nodes(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Children: nodes}, nil
+}
+
+// Format parses src and renders it back in the canonical layout of Document.String, so equivalent LMX compares
+// equal byte for byte.
+func Format(src string) (string, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return doc.String(), nil
+}
+
+type parser struct {
+	src   string
+	i     int
+	lines []int // byte offset of each line start
+}
+
+func (p *parser) pos(offset int) Position {
+	line := sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > offset }) - 1
+	return Position{Line: line + 1, Col: utf8.RuneCountInString(p.src[p.lines[line]:offset]) + 1}
+}
+
+func (p *parser) errorf(pos Position, format string, args ...interface{}) error {
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.i:], s) {
+		p.i += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) space() {
+	for p.i < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// name scans a tag or attribute name: a letter or underscore followed by letters, digits, '_', '-', '.' or ':'.
+func (p *parser) name() string {
+	start := p.i
+	for p.i < len(p.src) {
+		c := p.src[p.i]
+		letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+		if !letter && (p.i == start || !(c >= '0' && c <= '9' || c == '-' || c == '.' || c == ':')) {
+			break
+		}
+		p.i++
+	}
+	return p.src[start:p.i]
+}
+
+// nodes parses content until parent's closing tag (or the end of input at the top level).
+func (p *parser) nodes(parent *Element) ([]Node, error) {
+	var nodes []Node
+	verbatim := parent != nil && parent.Name == TagCodeBlock
+	for p.i < len(p.src) {
+		switch {
+		case strings.HasPrefix(p.src[p.i:], "</"):
+			start := p.pos(p.i)
+			p.i += 2
+			name := p.name()
+			p.space()
+			if !p.consume(">") {
+				return nil, p.errorf(p.pos(p.i), "expected > to end closing tag </%s", name)
+			}
+			if parent == nil {
+				return nil, p.errorf(start, "unexpected closing tag </%s>", name)
+			}
+			if name != parent.Name {
+				return nil, p.errorf(start, "closing tag </%s> does not match <%s> opened at %s", name, parent.Name, parent.Pos)
+			}
+			if verbatim {
+				return nodes, nil
+			}
+			return tidy(nodes), nil
+		case strings.HasPrefix(p.src[p.i:], "<!--"):
+			return nil, p.errorf(p.pos(p.i), "HTML comments are not supported; use {/* ... */}")
+		case p.src[p.i] == '<':
+			el, err := p.element()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, el)
+		case p.src[p.i] == '{':
+			expr, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, Expr(expr))
+		default:
+			j := strings.IndexAny(p.src[p.i:], "<{")
+			if j < 0 {
+				j = len(p.src) - p.i
+			}
+			raw := p.src[p.i : p.i+j]
+			p.i += j
+			if !verbatim {
+				raw = jsxText(raw)
+			}
+			if raw != "" {
+				nodes = append(nodes, Text(html.UnescapeString(raw)))
+			}
+		}
+	}
+	if parent != nil {
+		if voidTags[parent.Name] {
+			return nil, p.errorf(parent.Pos, "<%s> is never closed (write it as a self-closing <%s ... /> tag)", parent.Name, parent.Name)
+		}
+		return nil, p.errorf(parent.Pos, "<%s> is never closed", parent.Name)
+	}
+	return tidy(nodes), nil
+}
+
+func (p *parser) element() (*Element, error) {
+	start := p.i
+	p.i++ // '<'
+	name := p.name()
+	if name == "" {
+		return nil, p.errorf(p.pos(start), "expected tag name after <")
+	}
+	el := &Element{Name: name, Pos: p.pos(start)}
+	for {
+		p.space()
+		switch {
+		case p.i >= len(p.src):
+			return nil, p.errorf(el.Pos, "unterminated <%s> tag", name)
+		case p.consume("/>"):
+			return el, nil
+		case p.consume(">"):
+			children, err := p.nodes(el)
+			el.Children = children
+			return el, err
+		}
+		at := p.pos(p.i)
+		attr := p.name()
+		if attr == "" {
+			return nil, p.errorf(at, "unexpected %q in <%s> tag", p.src[p.i], name)
+		}
+		if _, dup := el.Get(attr); dup {
+			return nil, p.errorf(at, "duplicate attribute %s on <%s>", attr, name)
+		}
+		p.space()
+		if !p.consume("=") {
+			// A bare attribute is shorthand for attr={true}, as in JSX.
+			el.Attrs = append(el.Attrs, Attr{Name: attr, Value: "true", Expr: true})
+			continue
+		}
+		p.space()
+		if p.i >= len(p.src) {
+			return nil, p.errorf(at, "missing value for attribute %s", attr)
+		}
+		switch q := p.src[p.i]; q {
+		case '"', '\'':
+			end := strings.IndexByte(p.src[p.i+1:], q)
+			if end < 0 {
+				return nil, p.errorf(p.pos(p.i), "unterminated value for attribute %s", attr)
+			}
+			el.Attrs = append(el.Attrs, Attr{Name: attr, Value: html.UnescapeString(p.src[p.i+1 : p.i+1+end])})
+			p.i += end + 2
+		case '{':
+			expr, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			el.Attrs = append(el.Attrs, Attr{Name: attr, Value: expr, Expr: true})
+		default:
+			return nil, p.errorf(p.pos(p.i), "attribute %s value must be quoted or a {expression}", attr)
+		}
+	}
+}
+
+// expr scans a balanced {expression} starting at '{' and returns its contents. Braces inside string literals
+// do not count.
+func (p *parser) expr() (string, error) {
+	start := p.i
+	depth := 0
+	for p.i < len(p.src) {
+		switch c := p.src[p.i]; c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.i++
+				return p.src[start+1 : p.i-1], nil
+			}
+		case '"', '\'', '`':
+			for p.i++; p.i < len(p.src) && p.src[p.i] != c; p.i++ {
+				if p.src[p.i] == '\\' {
+					p.i++
+				}
+			}
+		}
+		p.i++
+	}
+	return "", p.errorf(p.pos(start), "unterminated {expression}")
+}
+
+// jsxText applies JSX whitespace rules: lines are trimmed where they meet a line break, blank lines are dropped
+// and the remaining lines are joined with single spaces. Text without a line break is kept as is.
+func jsxText(s string) string {
+	if !strings.Contains(s, "\n") {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for i, l := range lines {
+		if i > 0 {
+			l = strings.TrimLeft(l, " \t\r")
+		}
+		if i < len(lines)-1 {
+			l = strings.TrimRight(l, " \t\r")
+		}
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// tidy trims whitespace where text meets a block sibling or the start or end of a parent with block content,
+// where it carries no meaning, and drops text left empty. Whitespace between inline siblings is kept.
+func tidy(nodes []Node) []Node {
+	if allInline(nodes) {
+		return nodes
+	}
+	out := make([]Node, 0, len(nodes))
+	for i, n := range nodes {
+		if t, ok := n.(Text); ok {
+			s := string(t)
+			if i == 0 || !isInlineNode(nodes[i-1]) {
+				s = strings.TrimLeft(s, " \t\r\n")
+			}
+			if i == len(nodes)-1 || !isInlineNode(nodes[i+1]) {
+				s = strings.TrimRight(s, " \t\r\n")
+			}
+			if s == "" {
+				continue
+			}
+			n = Text(s)
+		}
+		out = append(out, n)
+	}
+	return out
+}
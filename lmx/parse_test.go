@@ -0,0 +1,143 @@
+package lmx
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	src := `<Style backgroundColor="#fff" bodyXPadding={24} />
+<Section>
+    <Heading level='1'>Tips &amp; tricks</Heading>
+    <Text>
+      Hi <Variable name="firstName" fallback="there" />,
+      see {/* note */} &#123;this&#125; <Link href="https://x.test/?a=1&amp;b=2">link</Link>
+    </Text>
+  <CodeBlock>line 1
+  line 2</CodeBlock>
+  <List ordered><ListItem>one</ListItem></List>
+</Section>`
+	doc, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<Style backgroundColor="#fff" bodyXPadding={24} />
+<Section>
+  <Heading level="1">Tips &amp; tricks</Heading>
+  <Text>Hi <Variable name="firstName" fallback="there" />, see {/* note */} &#123;this&#125; <Link href="https://x.test/?a=1&amp;b=2">link</Link></Text>
+  <CodeBlock>line 1
+  line 2</CodeBlock>
+  <List ordered={true}>
+    <ListItem>one</ListItem>
+  </List>
+</Section>`
+	if got := doc.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	section := doc.Children[1].(*Element)
+	if section.Pos != (Position{Line: 2, Col: 1}) || section.Children[0].(*Element).Pos != (Position{Line: 3, Col: 5}) {
+		t.Errorf("positions: %v %v", section.Pos, section.Children[0].(*Element).Pos)
+	}
+	if link, _ := section.Children[1].(*Element).Children[5].(*Element).Get("href"); link != "https://x.test/?a=1&b=2" {
+		t.Errorf("decoded href: %q", link)
+	}
+
+	again, err := Format(want)
+	if err != nil || again != want {
+		t.Errorf("Format is not idempotent:\n%s (%v)", again, err)
+	}
+}
+
+func TestFormat_InlineWhitespace(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"Hello {firstName}!", "Hello {firstName}!"},
+		{"00 {0}0", "00 {0}0"},
+		{"<Section>Hi <Bold>there</Bold><Divider /></Section>", "<Section>\n  Hi <Bold>there</Bold>\n  <Divider />\n</Section>"},
+		{"<Section> <Bold>a</Bold> <Italic>b</Italic> <Divider/> c </Section>",
+			"<Section>\n  <Bold>a</Bold> <Italic>b</Italic>\n  <Divider />\n  c\n</Section>"},
+		{"<Text>a&#10;b</Text>", "<Text>a&#10;b</Text>"},
+	}
+	for _, tt := range tests {
+		got, err := Format(tt.src)
+		if err != nil || got != tt.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", tt.src, got, err, tt.want)
+			continue
+		}
+		if again, _ := Format(got); again != got {
+			t.Errorf("Format(%q) is not idempotent: %q", tt.src, again)
+		}
+	}
+}
+
+func TestParse_BuilderOutput(t *testing.T) {
+	built := NewDocument(Heading(2, Text("a < b & {c}")), Paragraph(Text("x "), Bold(Text("y")), Text(" z")), Divider())
+	doc, err := Parse(built.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stripPos(doc.Children), built.Children) {
+		t.Errorf("parsed %#v", doc.Children)
+	}
+}
+
+func stripPos(nodes []Node) []Node {
+	for _, n := range nodes {
+		if e, ok := n.(*Element); ok {
+			e.Pos = Position{}
+			stripPos(e.Children)
+		}
+	}
+	return nodes
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		pos  Position
+		want string
+	}{
+		{"<Section>\n  <Text>hi</Section>", Position{2, 11}, "does not match <Text> opened at 2:3"},
+		{"<Style backgroundColor=\"#fff\">\n<Text>x</Text>", Position{1, 1}, "self-closing"},
+		{"<Text>ok</Text>\n</Section>", Position{2, 1}, "unexpected closing tag"},
+		{"<Image src=x />", Position{1, 12}, "must be quoted"},
+		{"<Text>{unclosed</Text>", Position{1, 7}, "unterminated"},
+		{"<Text a=\"1\" a=\"2\" />", Position{1, 13}, "duplicate attribute"},
+		{"<!-- c -->", Position{1, 1}, "HTML comments"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		var se *SyntaxError
+		if !errors.As(err, &se) || se.Pos != tt.pos || !strings.Contains(se.Msg, tt.want) {
+			t.Errorf("Parse(%q) = %v, want %s: ...%s", tt.src, err, tt.pos, tt.want)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	issues, err := LintString(`<Style backgroundColour="#fff" bodyXPadding="wide" />
+<Section>
+  <Style />
+  <Image src="a.png" />
+  <Heading level="4">x</Heading>
+  <ListItem>y</ListItem>
+  <Button>Go</Button>
+  <Marquee>z</Marquee>
+</Section>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.Pos.String()+" "+i.Rule)
+	}
+	want := []string{
+		"1:1 unknown-style-attr", "1:1 style-value",
+		"3:3 style-placement", "3:3 style-placement",
+		"4:3 missing-alt", "5:3 heading-level", "6:3 list-item", "7:3 missing-attr", "8:3 unknown-tag",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues:\n%v\nwant:\n%v", issues, want)
+	}
+}
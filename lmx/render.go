@@ -0,0 +1,180 @@
+package lmx
+
+import (
+	"io"
+	"strings"
+)
+
+const indentUnit = "  "
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "{", "&#123;", "}", "&#125;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "<", "&lt;", "\n", "&#10;")
+)
+
+// EscapeText escapes s for use as LMX character data. Braces are escaped because LMX treats them as expressions.
+func EscapeText(s string) string { return textEscaper.Replace(s) }
+
+// EscapeAttr escapes s for use inside a double-quoted attribute value.
+func EscapeAttr(s string) string { return attrEscaper.Replace(s) }
+
+// String renders the document: one block per line, nested blocks indented by two spaces, and runs of inline
+// content (text, expressions and inline elements) kept on a single line, so whitespace between them survives a
+// round trip through Parse. A document or element whose content is entirely inline is a single line.
+func (d *Document) String() string {
+	var b strings.Builder
+	if allInline(d.Children) {
+		for _, n := range d.Children {
+			writeInline(&b, n)
+		}
+		return b.String()
+	}
+	writeBlocks(&b, d.Children, 0)
+	return b.String()
+}
+
+// WriteTo writes the rendered document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, d.String())
+	return int64(n), err
+}
+
+// String renders e as a fragment, formatted as in Document.String.
+func (e *Element) String() string {
+	var b strings.Builder
+	writeBlock(&b, e, 0)
+	return b.String()
+}
+
+// writeBlocks writes nodes one line each, joining each run of adjacent inline nodes onto one line.
+func writeBlocks(b *strings.Builder, nodes []Node, depth int) {
+	for i := 0; i < len(nodes); {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if !isInlineNode(nodes[i]) {
+			writeBlock(b, nodes[i], depth)
+			i++
+			continue
+		}
+		b.WriteString(strings.Repeat(indentUnit, depth))
+		for ; i < len(nodes) && isInlineNode(nodes[i]); i++ {
+			writeInline(b, nodes[i])
+		}
+	}
+}
+
+func writeBlock(b *strings.Builder, n Node, depth int) {
+	indent := strings.Repeat(indentUnit, depth)
+	switch n := n.(type) {
+	case Text, Expr:
+		b.WriteString(indent)
+		writeInline(b, n)
+	case Raw:
+		lines := strings.Split(strings.TrimSpace(string(n)), "\n")
+		for i, line := range lines {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			if line != "" {
+				b.WriteString(indent)
+			}
+			b.WriteString(line)
+		}
+	case *Element:
+		b.WriteString(indent)
+		// CodeBlock content is verbatim: line breaks and indentation would become part of it.
+		if len(n.Children) == 0 || allInline(n.Children) || n.Name == TagCodeBlock {
+			writeInline(b, n)
+			return
+		}
+		writeOpen(b, n)
+		b.WriteByte('\n')
+		writeBlocks(b, n.Children, depth+1)
+		b.WriteByte('\n')
+		b.WriteString(indent)
+		writeClose(b, n)
+	}
+}
+
+// writeInline writes n on the current line. Line breaks in text are written as &#10;, since the parser folds
+// literal ones into a space.
+func writeInline(b *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case Text:
+		b.WriteString(strings.ReplaceAll(EscapeText(string(n)), "\n", "&#10;"))
+	case Expr:
+		b.WriteByte('{')
+		b.WriteString(string(n))
+		b.WriteByte('}')
+	case Raw:
+		b.WriteString(string(n))
+	case *Element:
+		if len(n.Children) == 0 {
+			b.WriteByte('<')
+			b.WriteString(n.Name)
+			writeAttrs(b, n)
+			b.WriteString(" />")
+			return
+		}
+		writeOpen(b, n)
+		for _, c := range n.Children {
+			if t, ok := c.(Text); ok && n.Name == TagCodeBlock {
+				b.WriteString(EscapeText(string(t))) // verbatim: line breaks are kept as written
+				continue
+			}
+			writeInline(b, c)
+		}
+		writeClose(b, n)
+	}
+}
+
+func writeOpen(b *strings.Builder, e *Element) {
+	b.WriteByte('<')
+	b.WriteString(e.Name)
+	writeAttrs(b, e)
+	b.WriteByte('>')
+}
+
+func writeClose(b *strings.Builder, e *Element) {
+	b.WriteString("</")
+	b.WriteString(e.Name)
+	b.WriteByte('>')
+}
+
+func writeAttrs(b *strings.Builder, e *Element) {
+	for _, a := range e.Attrs {
+		b.WriteByte(' ')
+		b.WriteString(a.Name)
+		if a.Expr {
+			b.WriteString("={")
+			b.WriteString(a.Value)
+			b.WriteByte('}')
+			continue
+		}
+		b.WriteString(`="`)
+		b.WriteString(EscapeAttr(a.Value))
+		b.WriteByte('"')
+	}
+}
+
+// allInline reports whether nodes can be rendered on one line: text, expressions and inline elements only.
+func allInline(nodes []Node) bool {
+	for _, n := range nodes {
+		if !isInlineNode(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// isInlineNode reports whether n flows with text: text, an expression or an inline element.
+func isInlineNode(n Node) bool {
+	switch n := n.(type) {
+	case Raw:
+		return false
+	case *Element:
+		return IsInline(n.Name)
+	}
+	return true
+}
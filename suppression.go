@@ -0,0 +1,215 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotSuppressed is returned by SuppressionManager.Remove when the contact is not suppressed, so removing it
+// would waste removal quota.
+var ErrNotSuppressed = errors.New("loops: contact is not suppressed")
+
+// ErrQuotaReserve is returned by SuppressionManager.Remove when a removal would take the remaining quota below the
+// configured reserve.
+var ErrQuotaReserve = errors.New("loops: suppression removal quota reserve reached")
+
+// QuotaSample is one observation of the suppression removal quota.
+type QuotaSample struct {
+	Time      time.Time `json:"time"`
+	Limit     float64   `json:"limit"`
+	Remaining float64   `json:"remaining"`
+	// Source is "status" (GET /contacts/suppression) or "removal" (DELETE /contacts/suppression).
+	Source string `json:"source"`
+}
+
+// SuppressionManager guards DeleteContactSuppression, which consumes a limited removal quota: every removal is
+// preceded by a status check and refused if the contact is not suppressed or the quota is down to the reserve.
+// Every quota value seen is recorded. A SuppressionManager is safe for concurrent use.
+type SuppressionManager struct {
+	client   *Client
+	reserve  float64
+	observe  func(QuotaSample)
+	maxHist  int
+	mu       sync.Mutex
+	history  []QuotaSample
+	removeMu sync.Mutex
+}
+
+// SuppressionOption configures a SuppressionManager.
+type SuppressionOption func(*SuppressionManager)
+
+// WithQuotaReserve keeps n removals in reserve: Remove is refused once Remaining would drop below n.
+func WithQuotaReserve(n int) SuppressionOption {
+	return func(m *SuppressionManager) { m.reserve = float64(n) }
+}
+
+// WithQuotaObserver calls fn with every quota sample, e.g. to export it as a metric.
+func WithQuotaObserver(fn func(QuotaSample)) SuppressionOption {
+	return func(m *SuppressionManager) { m.observe = fn }
+}
+
+// WithQuotaHistory sets how many samples QuotaHistory keeps (default 1000; oldest are dropped first).
+func WithQuotaHistory(n int) SuppressionOption {
+	return func(m *SuppressionManager) { m.maxHist = n }
+}
+
+// NewSuppressionManager returns a SuppressionManager using client.
+func NewSuppressionManager(client *Client, opts ...SuppressionOption) *SuppressionManager {
+	m := &SuppressionManager{client: client, maxHist: 1000}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *SuppressionManager) record(q ContactSuppressionRemovalQuota, source string) {
+	s := QuotaSample{Time: time.Now().UTC(), Limit: q.Limit, Remaining: q.Remaining, Source: source}
+	m.mu.Lock()
+	m.history = append(m.history, s)
+	if m.maxHist > 0 && len(m.history) > m.maxHist {
+		m.history = append(m.history[:0:0], m.history[len(m.history)-m.maxHist:]...)
+	}
+	m.mu.Unlock()
+	if m.observe != nil {
+		m.observe(s)
+	}
+}
+
+// Quota returns the most recent quota sample, if any.
+func (m *SuppressionManager) Quota() (QuotaSample, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.history) == 0 {
+		return QuotaSample{}, false
+	}
+	return m.history[len(m.history)-1], true
+}
+
+// QuotaHistory returns a copy of the recorded quota samples, oldest first.
+func (m *SuppressionManager) QuotaHistory() []QuotaSample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]QuotaSample(nil), m.history...)
+}
+
+// Status calls GetContactSuppression and records the quota it reports.
+func (m *SuppressionManager) Status(ctx context.Context, email, userID string) (*ContactSuppressionStatusResponse, error) {
+	status, err := m.client.GetContactSuppression(ctx, email, userID)
+	if err != nil {
+		return nil, err
+	}
+	m.record(status.RemovalQuota, "status")
+	return status, nil
+}
+
+// Remove removes the contact from the suppression list only if it is suppressed and the removal keeps at least the
+// reserve of quota; otherwise it returns ErrNotSuppressed or ErrQuotaReserve without calling the DELETE endpoint.
+// Removals are serialised so concurrent callers cannot overdraw the reserve.
+func (m *SuppressionManager) Remove(ctx context.Context, email, userID string) (*ContactSuppressionRemoveResponse, error) {
+	m.removeMu.Lock()
+	defer m.removeMu.Unlock()
+	status, err := m.Status(ctx, email, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !status.IsSuppressed {
+		return nil, ErrNotSuppressed
+	}
+	if q := status.RemovalQuota; q.Remaining-1 < m.reserve {
+		return nil, fmt.Errorf("%w: %v of %v remaining, reserve %v", ErrQuotaReserve, q.Remaining, q.Limit, m.reserve)
+	}
+	out, err := m.client.DeleteContactSuppression(ctx, email, userID)
+	if err != nil {
+		return nil, err
+	}
+	m.record(out.RemovalQuota, "removal")
+	return out, nil
+}
+
+// SuppressionAction is the outcome of reviewing one contact.
+type SuppressionAction string
+
+const (
+	SuppressionNotSuppressed SuppressionAction = "not_suppressed"
+	SuppressionSuppressed    SuppressionAction = "suppressed" // suppressed and left alone (review only)
+	SuppressionRemoved       SuppressionAction = "removed"
+	SuppressionRefused       SuppressionAction = "refused" // suppressed, but removal would break the reserve
+	SuppressionFailed        SuppressionAction = "failed"
+)
+
+// SuppressionReviewItem is one reviewed address.
+type SuppressionReviewItem struct {
+	Email  string            `json:"email"`
+	Action SuppressionAction `json:"action"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// SuppressionReport summarises a Review.
+type SuppressionReport struct {
+	Items         []SuppressionReviewItem `json:"items"`
+	Suppressed    int                     `json:"suppressed"` // includes removed and refused
+	NotSuppressed int                     `json:"notSuppressed"`
+	Removed       int                     `json:"removed"`
+	Refused       int                     `json:"refused"`
+	Failed        int                     `json:"failed"`
+	// Quota is the latest quota sample when the review finished, if any.
+	Quota *QuotaSample `json:"quota,omitempty"`
+}
+
+// Review checks the suppression status of each email in order. With remove set, suppressed contacts are also
+// removed via Remove until the reserve is reached; the rest are reported as refused. Per-address failures are
+// recorded in the report; an error is returned only if ctx is cancelled.
+func (m *SuppressionManager) Review(ctx context.Context, emails []string, remove bool) (*SuppressionReport, error) {
+	report := &SuppressionReport{}
+	for _, email := range emails {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		item := SuppressionReviewItem{Email: email}
+		var err error
+		if remove {
+			_, err = m.Remove(ctx, email, "")
+			switch {
+			case err == nil:
+				item.Action = SuppressionRemoved
+			case errors.Is(err, ErrNotSuppressed):
+				item.Action, err = SuppressionNotSuppressed, nil
+			case errors.Is(err, ErrQuotaReserve):
+				item.Action, err = SuppressionRefused, nil
+			}
+		} else {
+			var status *ContactSuppressionStatusResponse
+			if status, err = m.Status(ctx, email, ""); err == nil {
+				item.Action = SuppressionNotSuppressed
+				if status.IsSuppressed {
+					item.Action = SuppressionSuppressed
+				}
+			}
+		}
+		if err != nil {
+			item.Action, item.Error = SuppressionFailed, err.Error()
+		}
+		switch item.Action {
+		case SuppressionNotSuppressed:
+			report.NotSuppressed++
+		case SuppressionSuppressed:
+			report.Suppressed++
+		case SuppressionRemoved:
+			report.Suppressed++
+			report.Removed++
+		case SuppressionRefused:
+			report.Suppressed++
+			report.Refused++
+		case SuppressionFailed:
+			report.Failed++
+		}
+		report.Items = append(report.Items, item)
+	}
+	if q, ok := m.Quota(); ok {
+		report.Quota = &q
+	}
+	return report, nil
+}
@@ -0,0 +1,90 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// suppressionServer reports suppressed for emails in the set and decrements the quota on each removal.
+func suppressionServer(t *testing.T, remaining int, suppressed ...string) (*Client, func() int) {
+	t.Helper()
+	var mu sync.Mutex
+	set := make(map[string]bool)
+	for _, e := range suppressed {
+		set[e] = true
+	}
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		email := r.URL.Query().Get("email")
+		if r.Method == http.MethodDelete {
+			deletes++
+			remaining--
+			delete(set, email)
+			fmt.Fprintf(w, `{"success":true,"message":"removed","removalQuota":{"limit":10,"remaining":%d}}`, remaining)
+			return
+		}
+		fmt.Fprintf(w, `{"contact":{"id":"c","email":%q,"userId":null},"isSuppressed":%t,"removalQuota":{"limit":10,"remaining":%d}}`, email, set[email], remaining)
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("key", WithBaseURL(server.URL)), func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return deletes
+	}
+}
+
+func TestSuppressionManager_Remove(t *testing.T) {
+	client, deletes := suppressionServer(t, 3, "s1@example.com", "s2@example.com", "s3@example.com")
+	var observed []QuotaSample
+	m := NewSuppressionManager(client, WithQuotaReserve(2), WithQuotaObserver(func(s QuotaSample) { observed = append(observed, s) }))
+	ctx := context.Background()
+
+	if _, err := m.Remove(ctx, "clean@example.com", ""); !errors.Is(err, ErrNotSuppressed) {
+		t.Errorf("expected ErrNotSuppressed, got %v", err)
+	}
+	out, err := m.Remove(ctx, "s1@example.com", "")
+	if err != nil || out.RemovalQuota.Remaining != 2 {
+		t.Fatalf("first removal: %+v, %v", out, err)
+	}
+	if _, err := m.Remove(ctx, "s2@example.com", ""); !errors.Is(err, ErrQuotaReserve) {
+		t.Errorf("expected ErrQuotaReserve, got %v", err)
+	}
+	if deletes() != 1 {
+		t.Errorf("DELETE calls: %d", deletes())
+	}
+	if q, ok := m.Quota(); !ok || q.Remaining != 2 || q.Source != "status" {
+		t.Errorf("latest quota: %+v", q)
+	}
+	if len(m.QuotaHistory()) != 4 || len(observed) != 4 || observed[2].Source != "removal" {
+		t.Errorf("history: %+v", m.QuotaHistory())
+	}
+}
+
+func TestSuppressionManager_Review(t *testing.T) {
+	client, deletes := suppressionServer(t, 2, "s1@example.com", "s2@example.com")
+	m := NewSuppressionManager(client, WithQuotaReserve(1), WithQuotaHistory(2))
+	emails := []string{"s1@example.com", "clean@example.com", "s2@example.com", ""}
+
+	report, err := m.Review(context.Background(), emails, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Suppressed != 2 || report.NotSuppressed != 1 || report.Failed != 1 || report.Removed != 0 || deletes() != 0 {
+		t.Errorf("review only: %+v", report)
+	}
+
+	report, _ = m.Review(context.Background(), emails, true)
+	if report.Removed != 1 || report.Refused != 1 || report.Items[2].Action != SuppressionRefused || deletes() != 1 {
+		t.Errorf("review with removal: %+v", report)
+	}
+	if report.Quota == nil || report.Quota.Remaining != 1 || len(m.QuotaHistory()) != 2 {
+		t.Errorf("quota: %+v, history %d", report.Quota, len(m.QuotaHistory()))
+	}
+}
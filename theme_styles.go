@@ -0,0 +1,421 @@
+package loops
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// StyleValueError reports a ThemeStyles value that is not a valid color, size or number.
+type StyleValueError struct {
+	Field  string // JSON name, e.g. "bodyXPadding"
+	Value  string
+	Reason string
+}
+
+func (e *StyleValueError) Error() string {
+	return fmt.Sprintf("loops: theme style %s: invalid value %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// styleKind says how a ThemeStyles field is written in CSS and design tokens.
+type styleKind int
+
+const (
+	styleColor      styleKind = iota // CSS color
+	styleDimension                   // pixels
+	styleLineHeight                  // multiplier below 4, pixels from 4 (as the LMX preview renders it)
+	styleNumber                      // unitless
+	styleFontFamily
+	styleText
+)
+
+// ThemeStyleField describes a ThemeStyles field. It is the one table of style fields: the lmx package uses it
+// for <Style /> attributes.
+type ThemeStyleField struct {
+	Name    string // JSON name, which is also the <Style /> attribute name, e.g. "bodyXPadding"
+	CSSName string // kebab-case, e.g. "body-x-padding"
+	index   int
+	kind    styleKind
+}
+
+// ThemeStyleFields returns ThemeStyles' fields in declaration order.
+func ThemeStyleFields() []ThemeStyleField {
+	return append([]ThemeStyleField(nil), themeStyleFields...)
+}
+
+// Numeric reports whether the field holds a number (a size, line height or buttonTextFormat).
+func (f ThemeStyleField) Numeric() bool {
+	return f.kind == styleDimension || f.kind == styleLineHeight || f.kind == styleNumber
+}
+
+// Get returns the field's value in s as written in a <Style /> attribute (numbers without units), and whether
+// it is set.
+func (f ThemeStyleField) Get(s ThemeStyles) (string, bool) {
+	v := reflect.ValueOf(s).Field(f.index)
+	if v.Kind() == reflect.String {
+		return v.String(), v.String() != ""
+	}
+	return strconv.FormatFloat(v.Float(), 'f', -1, 64), v.Float() != 0
+}
+
+// Set sets the field in s from a <Style /> attribute value. A numeric field's value must be a number.
+func (f ThemeStyleField) Set(s *ThemeStyles, value string) error {
+	v := reflect.ValueOf(s).Elem().Field(f.index)
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return &StyleValueError{Field: f.Name, Value: value, Reason: "not a number"}
+	}
+	v.SetFloat(n)
+	return nil
+}
+
+// LineHeightCSS formats a line height for CSS as the LMX preview renders it: values below 4 are unitless
+// multipliers, larger ones pixels. It returns "" for zero.
+func LineHeightCSS(v float64) string {
+	switch {
+	case v == 0:
+		return ""
+	case v < 4:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + "px"
+}
+
+// themeStyleFields describes ThemeStyles' fields in declaration order; kinds follow from the field names.
+var themeStyleFields = func() []ThemeStyleField {
+	t := reflect.TypeOf(ThemeStyles{})
+	fields := make([]ThemeStyleField, t.NumField())
+	for i := range fields {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		f := ThemeStyleField{Name: name, CSSName: kebabCase(name), index: i, kind: styleText}
+		switch {
+		case strings.HasSuffix(name, "Color"):
+			f.kind = styleColor
+		case strings.HasSuffix(name, "FontFamily"):
+			f.kind = styleFontFamily
+		case strings.HasSuffix(name, "LineHeight"):
+			f.kind = styleLineHeight
+		case name == "buttonTextFormat":
+			f.kind = styleNumber
+		case t.Field(i).Type.Kind() == reflect.Float64:
+			f.kind = styleDimension
+		}
+		fields[i] = f
+	}
+	return fields
+}()
+
+func kebabCase(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Validate checks that colors are CSS hex, rgb(a) or hsl(a) colors (or "transparent"), that sizes are finite
+// and not negative (letter spacing may be negative) and that line heights are positive. Unset (zero) values are
+// valid. It returns nil or the errors.Join of a *StyleValueError per invalid field.
+func (s ThemeStyles) Validate() error {
+	v := reflect.ValueOf(s)
+	var errs []error
+	for _, f := range themeStyleFields {
+		fv := v.Field(f.index)
+		var err error
+		if fv.Kind() == reflect.String {
+			err = validateStyleString(f, fv.String())
+		} else {
+			err = validateStyleNumber(f, fv.Float())
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	hexColor   = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	funcColor  = regexp.MustCompile(`^(rgba?|hsla?)\(\s*([^()]*)\)$`)
+	colorParts = regexp.MustCompile(`[\s,/]+`)
+	colorPart  = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)(%|deg)?$`)
+)
+
+func validateStyleString(f ThemeStyleField, v string) error {
+	if v == "" || f.kind != styleColor {
+		return nil
+	}
+	if hexColor.MatchString(v) || strings.EqualFold(v, "transparent") {
+		return nil
+	}
+	if m := funcColor.FindStringSubmatch(strings.ToLower(v)); m != nil {
+		parts := colorParts.Split(strings.TrimSpace(m[2]), -1)
+		ok := len(parts) == 3 || len(parts) == 4
+		for _, p := range parts {
+			ok = ok && colorPart.MatchString(p)
+		}
+		if ok {
+			return nil
+		}
+	}
+	return &StyleValueError{Field: f.Name, Value: v, Reason: "not a hex, rgb(), hsl() or transparent color"}
+}
+
+func validateStyleNumber(f ThemeStyleField, v float64) error {
+	text := strconv.FormatFloat(v, 'g', -1, 64)
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return &StyleValueError{Field: f.Name, Value: text, Reason: "not a finite number"}
+	case v < 0 && !strings.HasSuffix(f.Name, "LetterSpacing"):
+		return &StyleValueError{Field: f.Name, Value: text, Reason: "must not be negative"}
+	}
+	return nil
+}
+
+// cssValue formats a set field for CSS; ok is false for unset fields.
+func (f ThemeStyleField) cssValue(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.String {
+		return v.String(), v.String() != ""
+	}
+	n := v.Float()
+	if n == 0 {
+		return "", false
+	}
+	switch f.kind {
+	case styleLineHeight:
+		return LineHeightCSS(n), true
+	case styleDimension:
+		return strconv.FormatFloat(n, 'f', -1, 64) + "px", true
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64), true
+}
+
+// parseValue parses a CSS or token value for f and validates it.
+func (f ThemeStyleField) parseValue(s string) (reflect.Value, error) {
+	s = strings.TrimSpace(s)
+	if f.kind == styleColor || f.kind == styleFontFamily || f.kind == styleText {
+		return reflect.ValueOf(s), validateStyleString(f, s)
+	}
+	num := s
+	if f.kind == styleDimension || f.kind == styleLineHeight {
+		num = strings.TrimSuffix(s, "px")
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		reason := "not a number"
+		if f.kind == styleDimension {
+			reason = "not a number of pixels"
+		}
+		return reflect.Value{}, &StyleValueError{Field: f.Name, Value: s, Reason: reason}
+	}
+	if f.kind == styleLineHeight && num != s && n < 4 {
+		// Small pixel line heights would read back as multipliers.
+		return reflect.Value{}, &StyleValueError{Field: f.Name, Value: s, Reason: "pixel line heights below 4px are not supported"}
+	}
+	return reflect.ValueOf(n), validateStyleNumber(f, n)
+}
+
+// CSS returns the set styles as CSS custom properties in a rule for selector (":root" if empty), named
+// --<prefix>-<kebab-case field> ("loops" if prefix is empty), e.g. --loops-body-x-padding: 24px. Sizes are in px;
+// line heights below 4 are unitless multipliers.
+func (s ThemeStyles) CSS(selector, prefix string) string {
+	if selector == "" {
+		selector = ":root"
+	}
+	if prefix == "" {
+		prefix = "loops"
+	}
+	var b strings.Builder
+	b.WriteString(selector + " {\n")
+	v := reflect.ValueOf(s)
+	for _, f := range themeStyleFields {
+		if val, ok := f.cssValue(v.Field(f.index)); ok {
+			fmt.Fprintf(&b, "  --%s-%s: %s;\n", prefix, f.CSSName, val)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var cssDeclaration = regexp.MustCompile(`--([A-Za-z0-9_-]+)\s*:\s*([^;}]*)`)
+
+// ParseCSS reads the custom properties written by CSS back into ThemeStyles. Declarations without the
+// --<prefix>- prefix are ignored; an unknown prefixed name or an invalid value is an error.
+func ParseCSS(css, prefix string) (ThemeStyles, error) {
+	if prefix == "" {
+		prefix = "loops"
+	}
+	byCSS := make(map[string]ThemeStyleField, len(themeStyleFields))
+	for _, f := range themeStyleFields {
+		byCSS[f.CSSName] = f
+	}
+	var s ThemeStyles
+	v := reflect.ValueOf(&s).Elem()
+	for _, m := range cssDeclaration.FindAllStringSubmatch(css, -1) {
+		name, ok := strings.CutPrefix(m[1], prefix+"-")
+		if !ok {
+			continue
+		}
+		f, ok := byCSS[name]
+		if !ok {
+			return ThemeStyles{}, fmt.Errorf("loops: unknown theme style property --%s", m[1])
+		}
+		val, err := f.parseValue(m[2])
+		if err != nil {
+			return ThemeStyles{}, err
+		}
+		v.Field(f.index).Set(val)
+	}
+	return s, nil
+}
+
+// DesignToken is a token in the W3C Design Tokens Community Group format.
+type DesignToken struct {
+	Type  string      `json:"$type,omitempty"`
+	Value interface{} `json:"$value"`
+}
+
+// DesignTokens returns the set styles as design tokens keyed by field name. Colors have $type "color", sizes
+// "dimension" (as "24px" strings), multiplier line heights and buttonTextFormat "number" and the font family
+// "fontFamily"; bodyFontCategory has no token type.
+func (s ThemeStyles) DesignTokens() map[string]DesignToken {
+	tokens := make(map[string]DesignToken)
+	v := reflect.ValueOf(s)
+	for _, f := range themeStyleFields {
+		fv := v.Field(f.index)
+		val, ok := f.cssValue(fv)
+		if !ok {
+			continue
+		}
+		t := DesignToken{Value: val}
+		switch f.kind {
+		case styleColor:
+			t.Type = "color"
+		case styleFontFamily:
+			t.Type = "fontFamily"
+		case styleDimension:
+			t.Type = "dimension"
+		case styleLineHeight, styleNumber:
+			t.Type, t.Value = "number", fv.Float()
+			if strings.HasSuffix(val, "px") {
+				t.Type, t.Value = "dimension", val
+			}
+		}
+		tokens[f.Name] = t
+	}
+	return tokens
+}
+
+// MarshalDesignTokens returns the styles as an indented design token file, with the tokens in a group at the
+// dot-separated path group (for example "brand.email"), or at the top level if group is empty.
+func (s ThemeStyles) MarshalDesignTokens(group string) ([]byte, error) {
+	var doc interface{} = s.DesignTokens()
+	if group != "" {
+		parts := strings.Split(group, ".")
+		for i := len(parts) - 1; i >= 0; i-- {
+			doc = map[string]interface{}{parts[i]: doc}
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ParseDesignTokens reads ThemeStyles from a design token file written by MarshalDesignTokens or another tool,
+// taking the tokens in the group at the dot-separated path group (the top level if empty) whose names match
+// ThemeStyles' JSON field names. Other tokens and groups are ignored. Dimension values may be "24px" strings or
+// {"value": 24, "unit": "px"} objects, and colors strings or objects with a "hex" member.
+func ParseDesignTokens(data []byte, group string) (ThemeStyles, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return ThemeStyles{}, err
+	}
+	if group != "" {
+		for _, part := range strings.Split(group, ".") {
+			raw, ok := doc[part]
+			if !ok {
+				return ThemeStyles{}, fmt.Errorf("loops: design token group %q not found", group)
+			}
+			doc = nil
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				return ThemeStyles{}, fmt.Errorf("loops: design token group %q: %w", group, err)
+			}
+		}
+	}
+	var s ThemeStyles
+	v := reflect.ValueOf(&s).Elem()
+	for _, f := range themeStyleFields {
+		raw, ok := doc[f.Name]
+		if !ok {
+			continue
+		}
+		var token struct {
+			Value json.RawMessage `json:"$value"`
+		}
+		if err := json.Unmarshal(raw, &token); err != nil || token.Value == nil {
+			return ThemeStyles{}, fmt.Errorf("loops: design token %s has no $value", f.Name)
+		}
+		text, err := tokenValueString(token.Value)
+		if err != nil {
+			return ThemeStyles{}, fmt.Errorf("loops: design token %s: %w", f.Name, err)
+		}
+		val, err := f.parseValue(text)
+		if err != nil {
+			return ThemeStyles{}, err
+		}
+		v.Field(f.index).Set(val)
+	}
+	return s, nil
+}
+
+// tokenValueString flattens the $value forms ThemeStyles can hold into CSS text.
+func tokenValueString(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case len(raw) > 0 && raw[0] == '"':
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case len(raw) > 0 && raw[0] == '[': // fontFamily stack
+		var stack []string
+		if err := json.Unmarshal(raw, &stack); err != nil {
+			return "", err
+		}
+		return strings.Join(stack, ", "), nil
+	case len(raw) > 0 && raw[0] == '{':
+		var obj struct {
+			Value *float64 `json:"value"`
+			Unit  string   `json:"unit"`
+			Hex   string   `json:"hex"`
+		}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return "", err
+		}
+		if obj.Hex != "" {
+			return obj.Hex, nil
+		}
+		if obj.Value == nil || (obj.Unit != "" && obj.Unit != "px") {
+			return "", fmt.Errorf("unsupported value %s (want px)", raw)
+		}
+		return strconv.FormatFloat(*obj.Value, 'f', -1, 64) + obj.Unit, nil
+	default:
+		var n float64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return "", fmt.Errorf("unsupported value %s", raw)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	}
+}
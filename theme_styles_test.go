@@ -0,0 +1,150 @@
+package loops
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testStyles = ThemeStyles{
+	BackgroundColor:       "#f4f4f5",
+	BodyXPadding:          24,
+	BodyFontFamily:        "Inter",
+	BodyFontCategory:      "sans-serif",
+	ButtonBodyColor:       "rgb(0, 102, 255)",
+	ButtonTextFormat:      1,
+	TextBaseLineHeight:    1.5,
+	TextBaseLetterSpacing: -0.2,
+	Heading1LineHeight:    40,
+}
+
+func TestThemeStyles_CSS(t *testing.T) {
+	want := `.email {
+  --brand-background-color: #f4f4f5;
+  --brand-body-x-padding: 24px;
+  --brand-body-font-family: Inter;
+  --brand-body-font-category: sans-serif;
+  --brand-button-body-color: rgb(0, 102, 255);
+  --brand-button-text-format: 1;
+  --brand-text-base-line-height: 1.5;
+  --brand-text-base-letter-spacing: -0.2px;
+  --brand-heading1-line-height: 40px;
+}
+`
+	css := testStyles.CSS(".email", "brand")
+	if css != want {
+		t.Errorf("got:\n%s\nwant:\n%s", css, want)
+	}
+	got, err := ParseCSS(css+"body { color: var(--brand-background-color); --other-x: 1px; }", "brand")
+	if err != nil || got != testStyles {
+		t.Errorf("round trip: %+v, %v", got, err)
+	}
+	if !strings.HasPrefix(ThemeStyles{}.CSS("", ""), ":root {\n}") {
+		t.Errorf("defaults: %q", ThemeStyles{}.CSS("", ""))
+	}
+
+	for css, want := range map[string]string{
+		"--loops-body-x-padding: 2rem;":     "bodyXPadding",
+		"--loops-body-color: blue;":         "bodyColor",
+		"--loops-heading1-line-height: 2px": "pixel line heights",
+		"--loops-border-widht: 1px;":        "unknown theme style property --loops-border-widht",
+	} {
+		if _, err := ParseCSS(css, ""); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseCSS(%q) = %v, want error containing %q", css, err, want)
+		}
+	}
+}
+
+func TestThemeStyles_DesignTokens(t *testing.T) {
+	b, err := testStyles.MarshalDesignTokens("brand.email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"brand": {`, `"email": {`,
+		`"backgroundColor": {
+        "$type": "color",
+        "$value": "#f4f4f5"`,
+		`"bodyXPadding": {
+        "$type": "dimension",
+        "$value": "24px"`,
+		`"textBaseLineHeight": {
+        "$type": "number",
+        "$value": 1.5`,
+		`"heading1LineHeight": {
+        "$type": "dimension",
+        "$value": "40px"`,
+		`"bodyFontCategory": {
+        "$value": "sans-serif"`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("tokens missing %s:\n%s", want, b)
+		}
+	}
+	got, err := ParseDesignTokens(b, "brand.email")
+	if err != nil || got != testStyles {
+		t.Errorf("round trip: %+v, %v", got, err)
+	}
+
+	// Object-valued dimensions and colors (newer format) and font stacks.
+	src := `{"bodyXPadding": {"$type": "dimension", "$value": {"value": 12, "unit": "px"}},
+		"textLinkColor": {"$type": "color", "$value": {"colorSpace": "srgb", "components": [0, 0, 1], "hex": "#0000ff"}},
+		"bodyFontFamily": {"$type": "fontFamily", "$value": ["Inter", "Arial"]},
+		"spacing": {"small": {"$value": "4px"}}}`
+	got, err = ParseDesignTokens([]byte(src), "")
+	if err != nil || got != (ThemeStyles{BodyXPadding: 12, TextLinkColor: "#0000ff", BodyFontFamily: "Inter, Arial"}) {
+		t.Errorf("object values: %+v, %v", got, err)
+	}
+	if _, err := ParseDesignTokens([]byte(`{"bodyXPadding": {"$value": {"value": 1, "unit": "rem"}}}`), ""); err == nil {
+		t.Error("rem dimension accepted")
+	}
+	if _, err := ParseDesignTokens([]byte(`{}`), "brand"); err == nil {
+		t.Error("missing group accepted")
+	}
+}
+
+func TestThemeStyleFields_GetSet(t *testing.T) {
+	var copied ThemeStyles
+	for _, f := range ThemeStyleFields() {
+		if v, ok := f.Get(testStyles); ok {
+			if err := f.Set(&copied, v); err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+	}
+	if copied != testStyles {
+		t.Errorf("copied through Get/Set: %+v", copied)
+	}
+	var bad *StyleValueError
+	if f := ThemeStyleFields()[1]; !f.Numeric() || !errors.As(f.Set(&copied, "wide"), &bad) {
+		t.Errorf("%s: expected a numeric field to reject a non-number", f.Name)
+	}
+	if got := LineHeightCSS(1.5) + " " + LineHeightCSS(40) + " " + LineHeightCSS(0); got != "1.5 40px " {
+		t.Errorf("LineHeightCSS: %q", got)
+	}
+}
+
+func TestThemeStyles_Validate(t *testing.T) {
+	if err := testStyles.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	valid := []string{"#fff", "#FFFA", "#00aaff", "#00aaff80", "transparent", "rgba(0,0,0,0.5)", "hsl(210deg 50% 40% / 50%)"}
+	for _, c := range valid {
+		if err := (ThemeStyles{BodyColor: c}).Validate(); err != nil {
+			t.Errorf("%s: %v", c, err)
+		}
+	}
+	err := ThemeStyles{BodyColor: "#ggg", TextBaseColor: "rgb(1,2)", BorderWidth: -1, TextBaseLetterSpacing: -1}.Validate()
+	var styleErr *StyleValueError
+	if !errors.As(err, &styleErr) || styleErr.Field != "bodyColor" {
+		t.Fatalf("expected StyleValueError for bodyColor, got %v", err)
+	}
+	for _, want := range []string{"bodyColor", "textBaseColor", "borderWidth: invalid value \"-1\": must not be negative"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "textBaseLetterSpacing") {
+		t.Errorf("negative letter spacing rejected: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package loops
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the string formats Timestamp parses, most likely first. Values without a zone are UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Timestamp is a createdAt, updatedAt or lastUpdated value. It decodes ISO 8601 strings and Unix epoch numbers
+// (seconds or milliseconds) into Time, and keeps the JSON it was decoded from so it encodes back identically.
+// null, "" and values in an unrecognized format leave Time zero.
+type Timestamp struct {
+	Time time.Time
+	raw  string // JSON as received
+}
+
+// NewTimestamp returns a Timestamp for t. It encodes as an RFC 3339 string.
+func NewTimestamp(t time.Time) Timestamp { return Timestamp{Time: t} }
+
+// IsZero reports whether the timestamp has no time (null, empty or unparseable).
+func (t Timestamp) IsZero() bool { return t.Time.IsZero() }
+
+// Equal reports whether t and u are the same instant. Two timestamps without a time are equal if their raw
+// values are.
+func (t Timestamp) Equal(u Timestamp) bool {
+	if t.IsZero() && u.IsZero() {
+		return t.String() == u.String()
+	}
+	return t.Time.Equal(u.Time)
+}
+
+// Compare returns -1, 0 or +1 as t is before, equal to or after u. A zero timestamp is before any other.
+func (t Timestamp) Compare(u Timestamp) int {
+	switch {
+	case t.Time.Before(u.Time):
+		return -1
+	case t.Time.After(u.Time):
+		return 1
+	}
+	return 0
+}
+
+// String returns the value as received from the API ("" for null), or Time in RFC 3339 for NewTimestamp.
+func (t Timestamp) String() string {
+	if t.raw == "" {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Time.Format(time.RFC3339Nano)
+	}
+	var s string
+	if json.Unmarshal([]byte(t.raw), &s) == nil {
+		return s
+	}
+	if t.raw == "null" {
+		return ""
+	}
+	return t.raw
+}
+
+// MarshalJSON encodes the value as received; a Timestamp not decoded from JSON encodes Time in RFC 3339, or
+// null when zero.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.raw != "" {
+		return []byte(t.raw), nil
+	}
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time.Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON decodes a string, number or null. It fails only for other JSON types.
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	*t = Timestamp{raw: string(b)}
+	if string(b) == "null" {
+		return nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		t.Time = parseTimestamp(strings.TrimSpace(v))
+	case json.Number:
+		t.Time = epochTimestamp(v.String())
+	default:
+		return &json.UnmarshalTypeError{Value: string(b), Type: timestampType}
+	}
+	return nil
+}
+
+var timestampType = reflect.TypeOf(Timestamp{})
+
+func parseTimestamp(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range timestampLayouts {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts
+		}
+	}
+	return epochTimestamp(s)
+}
+
+// epochTimestamp parses Unix seconds, or milliseconds for values too large to be seconds in this era.
+func epochTimestamp(s string) time.Time {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	if n >= 1e11 {
+		return time.UnixMilli(int64(n)).UTC()
+	}
+	return time.Unix(int64(n), int64((n-float64(int64(n)))*1e9)).UTC()
+}
+
+// SortCampaignsByUpdatedAt sorts campaigns by UpdatedAt, most recent first. Campaigns without a time go last.
+func SortCampaignsByUpdatedAt(campaigns []CampaignListItem) {
+	sort.SliceStable(campaigns, func(i, j int) bool { return campaigns[i].UpdatedAt.Compare(campaigns[j].UpdatedAt) > 0 })
+}
+
+// SortThemesByUpdatedAt sorts themes by UpdatedAt, most recent first. Themes without a time go last.
+func SortThemesByUpdatedAt(themes []Theme) {
+	sort.SliceStable(themes, func(i, j int) bool { return themes[i].UpdatedAt.Compare(themes[j].UpdatedAt) > 0 })
+}
+
+// SortTransactionalsByLastUpdated sorts transactional emails by LastUpdated, most recent first. Emails without a
+// time go last.
+func SortTransactionalsByLastUpdated(emails []TransactionalEmail) {
+	sort.SliceStable(emails, func(i, j int) bool { return emails[i].LastUpdated.Compare(emails[j].LastUpdated) > 0 })
+}
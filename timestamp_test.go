@@ -0,0 +1,79 @@
+package loops
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// timestampOf decodes s as a JSON string, as if it came from the API.
+func timestampOf(s string) Timestamp {
+	var t Timestamp
+	if err := json.Unmarshal([]byte(strconv.Quote(s)), &t); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestTimestamp_JSON(t *testing.T) {
+	want := time.Date(2026, 4, 24, 9, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		in   string
+		want time.Time
+	}{
+		{`"2026-04-24T09:30:00Z"`, want},
+		{`"2026-04-24T09:30:00.000Z"`, want},
+		{`"2026-04-24T11:30:00+02:00"`, want},
+		{`"2026-04-24T09:30:00"`, want},
+		{`"2026-04-24 09:30:00"`, want},
+		{`"2026-04-24"`, time.Date(2026, 4, 24, 0, 0, 0, 0, time.UTC)},
+		{`1777023000`, want},
+		{`1777023000000`, want},
+		{`"1777023000000"`, want},
+		{`null`, time.Time{}},
+		{`""`, time.Time{}},
+		{`"last tuesday"`, time.Time{}},
+	} {
+		var c CampaignListItem
+		if err := json.Unmarshal([]byte(`{"updatedAt":`+tc.in+`}`), &c); err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if !c.UpdatedAt.Time.Equal(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.in, c.UpdatedAt.Time, tc.want)
+		}
+		if b, _ := json.Marshal(c.UpdatedAt); string(b) != tc.in {
+			t.Errorf("%s: marshals as %s", tc.in, b)
+		}
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`true`), &ts); err == nil {
+		t.Error("boolean accepted")
+	}
+	if b, _ := json.Marshal(NewTimestamp(want)); string(b) != `"2026-04-24T09:30:00Z"` {
+		t.Errorf("NewTimestamp: %s", b)
+	}
+	if b, _ := json.Marshal(Timestamp{}); string(b) != `null` {
+		t.Errorf("zero: %s", b)
+	}
+	if s := timestampOf("2026-04-24T09:30:00.000Z").String(); s != "2026-04-24T09:30:00.000Z" {
+		t.Errorf("String: %q", s)
+	}
+	if !timestampOf("2026-04-24T11:30:00+02:00").Equal(NewTimestamp(want)) || timestampOf("x").Equal(timestampOf("y")) {
+		t.Error("Equal")
+	}
+}
+
+func TestSortCampaignsByUpdatedAt(t *testing.T) {
+	campaigns := []CampaignListItem{
+		{CampaignID: "none"},
+		{CampaignID: "old", UpdatedAt: timestampOf("2024-01-01T00:00:00Z")},
+		{CampaignID: "new", UpdatedAt: timestampOf("2026-01-01T00:00:00Z")},
+	}
+	SortCampaignsByUpdatedAt(campaigns)
+	if campaigns[0].CampaignID != "new" || campaigns[1].CampaignID != "old" || campaigns[2].CampaignID != "none" {
+		t.Errorf("order: %+v", campaigns)
+	}
+}
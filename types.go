@@ -33,7 +33,9 @@ type Contact struct {
 	UserGroup    string          `json:"userGroup,omitempty"`
 	UserID       *string         `json:"userId,omitempty"`
 	MailingLists map[string]bool `json:"mailingLists,omitempty"`
-	OptInStatus  *string         `json:"optInStatus,omitempty"` // "accepted" | "pending" | "rejected"
+	OptInStatus  *OptInStatus    `json:"optInStatus,omitempty"`
+	// Extra holds custom contact properties returned alongside the standard fields.
+	Extra map[string]interface{} `json:"-"`
 }
 
 // --- Contact create/update (ContactRequest, ContactUpdateRequest) ---
@@ -119,15 +121,15 @@ type ContactSuppressionRemoveResponse struct {
 
 // ContactPropertyCreateRequest is the body for POST /contacts/properties (OpenAPI: name, type required).
 type ContactPropertyCreateRequest struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Name string       `json:"name"`
+	Type PropertyType `json:"type"`
 }
 
 // ContactProperty is a single property (OpenAPI: key, label, type required).
 type ContactProperty struct {
-	Key   string `json:"key"`
-	Label string `json:"label"`
-	Type  string `json:"type"`
+	Key   string       `json:"key"`
+	Label string       `json:"label"`
+	Type  PropertyType `json:"type"`
 }
 
 // ContactPropertySuccessResponse is the 200 response for create property.
@@ -220,7 +222,7 @@ type TransactionalFailureResponse struct {
 type TransactionalEmail struct {
 	ID             string   `json:"id"`
 	Name           string   `json:"name"`
-	LastUpdated    string   `json:"lastUpdated"`
+	LastUpdated    Timestamp `json:"lastUpdated"`
 	DataVariables  []string `json:"dataVariables"`
 }
 